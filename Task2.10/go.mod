@@ -2,4 +2,9 @@ module github.com/PavelBradnitski/WbTechL2/Task2.10
 
 go 1.24.1
 
-require github.com/spf13/pflag v1.0.7
+require (
+	github.com/PavelBradnitski/WbTechL2/field v0.0.0
+	github.com/spf13/pflag v1.0.7
+)
+
+replace github.com/PavelBradnitski/WbTechL2/field => ../field
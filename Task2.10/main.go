@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/PavelBradnitski/WbTechL2/field"
 	"github.com/spf13/pflag"
 )
 
@@ -298,51 +299,13 @@ func extractKey(line string, opt Options) key {
 
 // extractColumnValue возвращает значение N-й (1-based) колонки из строки,
 // используя указанный разделитель. Если колонки нет — возвращает пустую строку.
+// Колонки выделяются общим пакетом field, поэтому sort -k N и cut -f N
+// одинаково понимают табуляцию, пустые поля и многосимвольные разделители.
 func extractColumnValue(line, delimiter string, columnIndex int) string {
 	if columnIndex <= 0 {
 		return line
 	}
-	target := columnIndex - 1
-	if delimiter == "" {
-		return ""
-	}
-	if len(delimiter) == 1 {
-		// Оптимизация для одиночного байта-разделителя
-		d := delimiter[0]
-		current := 0
-		start := 0
-		for i := 0; i < len(line); i++ {
-			if line[i] == d {
-				if current == target {
-					return line[start:i]
-				}
-				current++
-				start = i + 1
-			}
-		}
-		if current == target {
-			return line[start:]
-		}
-		return ""
-	}
-	// Общий случай для строкового разделителя
-	current := 0
-	start := 0
-	for {
-		if current == target {
-			pos := strings.Index(line[start:], delimiter)
-			if pos == -1 {
-				return line[start:]
-			}
-			return line[start : start+pos]
-		}
-		pos := strings.Index(line[start:], delimiter)
-		if pos == -1 {
-			return ""
-		}
-		start = start + pos + len(delimiter)
-		current++
-	}
+	return field.Splitter{Delimiter: delimiter}.Field(line, columnIndex)
 }
 
 // IsSortedReader выполняет потоковую проверку отсортированности ввода без загрузки
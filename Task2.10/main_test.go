@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field/fieldtest"
 )
 
 func TestExpandCombinedShortFlags(t *testing.T) {
@@ -135,3 +137,19 @@ func TestIsSortedReaderUnsorted(t *testing.T) {
 		t.Fatalf("expected unsorted, got ok=%v idx=%d", ok, idx)
 	}
 }
+
+// sort -k N должен видеть те же колонки, что cut -f N
+func TestExtractColumnValueConformance(t *testing.T) {
+	for _, c := range fieldtest.Cases {
+		if !c.Delimited() {
+			continue
+		}
+		t.Run(c.Name, func(t *testing.T) {
+			for n := 1; n <= len(c.Fields)+1; n++ {
+				if got := extractColumnValue(c.Line, c.Splitter.Delimiter, n); got != c.Column(n) {
+					t.Errorf("col%d mismatch: got %q want %q", n, got, c.Column(n))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+// parseBinaryFiles проверяет значение --binary-files с учётом сокращений -a и -I
+func parseBinaryFiles(mode string, text, withoutMatch bool) (string, error) {
+	switch {
+	case text:
+		return grep.BinaryFilesText, nil
+	case withoutMatch:
+		return grep.BinaryFilesWithoutMatch, nil
+	}
+	switch mode {
+	case grep.BinaryFilesBinary, grep.BinaryFilesText, grep.BinaryFilesWithoutMatch:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("некорректное значение --binary-files: %q", mode)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+func TestSearchReaderBinaryModes(t *testing.T) {
+	input := "header\x00\nERROR core dumped\ntrailer\n"
+
+	tests := []struct {
+		name     string
+		params   *SearchParams
+		expected string
+		matches  int
+	}{
+		{
+			name:     "по умолчанию только сообщение",
+			params:   &SearchParams{binaryFiles: grep.BinaryFilesBinary},
+			expected: "Binary file core matches\n",
+			matches:  1,
+		},
+		{
+			name:     "-a печатает строки",
+			params:   &SearchParams{binaryFiles: grep.BinaryFilesText},
+			expected: "ERROR core dumped\n",
+			matches:  1,
+		},
+		{
+			name:     "-I пропускает файл",
+			params:   &SearchParams{binaryFiles: grep.BinaryFilesWithoutMatch},
+			expected: "",
+			matches:  0,
+		},
+		{
+			name:     "-c считает строки",
+			params:   &SearchParams{binaryFiles: grep.BinaryFilesBinary, countOnly: true},
+			expected: "1\n",
+			matches:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.fixedString = true
+			m, err := newMatcher([]string{"ERROR"}, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			stats, err := searchReader(context.Background(), strings.NewReader(input), "core", grep.NewSearcher(m, tt.params.options()), tt.params, false, &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.expected || stats.matchedLines != tt.matches {
+				t.Errorf("got %q (%d), want %q (%d)", out.String(), stats.matchedLines, tt.expected, tt.matches)
+			}
+		})
+	}
+}
+
+func TestSearchReaderNullData(t *testing.T) {
+	params := &SearchParams{fixedString: true, nullData: true}
+	m, err := newMatcher([]string{"two"}, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	input := "one\x00line\ntwo\x00three\x00"
+	if _, err := searchReader(context.Background(), strings.NewReader(input), "-", grep.NewSearcher(m, params.options()), params, false, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "line\ntwo\x00" {
+		t.Errorf("got %q", out.String())
+	}
+}
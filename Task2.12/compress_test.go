@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bzip2Fixture - "start\nERROR in archive\nend\n", сжатое bzip2 -9
+var bzip2Fixture = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x15, 0xbf, 0x55, 0xee, 0x00, 0x00,
+	0x0b, 0xd7, 0x80, 0x00, 0x10, 0x40, 0x00, 0x02, 0x00, 0x90, 0x00, 0x2e, 0x61, 0x1d, 0x00, 0x20,
+	0x00, 0x22, 0x9a, 0x19, 0x3c, 0xa6, 0x08, 0x53, 0x00, 0x04, 0xd2, 0x89, 0x80, 0xa8, 0x10, 0x01,
+	0x3d, 0xb1, 0x0d, 0x35, 0x35, 0x86, 0x36, 0xbf, 0x17, 0x72, 0x45, 0x38, 0x50, 0x90, 0x15, 0xbf,
+	0x55, 0xee,
+}
+
+// gzipMembers сжимает каждую часть отдельным членом gzip, как при склейке ротированных логов
+func gzipMembers(t *testing.T, parts ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, part := range parts {
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write([]byte(part)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestRunSearchZip(t *testing.T) {
+	dir := t.TempDir()
+	gz := filepath.Join(dir, "app.log.1.gz")
+	bz := filepath.Join(dir, "app.log.2.bz2")
+	plain := filepath.Join(dir, "app.log")
+	// Текст с началом сигнатуры bzip2 ищется как обычный файл
+	lookalike := filepath.Join(dir, "notes.txt")
+	files := map[string][]byte{
+		gz:        gzipMembers(t, "first\nERROR one\n", "ERROR two\nlast\n"),
+		bz:        bzip2Fixture,
+		plain:     []byte("ERROR plain\n"),
+		lookalike: []byte("BZhello\nERROR lookalike\n"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--search-zip", "-n", "-b", "ERROR", gz, bz, plain, lookalike}, &stdout, &stderr)
+	if code != exitMatch {
+		t.Fatalf("код завершения %d, stderr: %s", code, stderr.String())
+	}
+	want := gz + ":2:6:ERROR one\n" +
+		gz + ":3:16:ERROR two\n" +
+		bz + ":2:6:ERROR in archive\n" +
+		plain + ":1:0:ERROR plain\n" +
+		lookalike + ":2:8:ERROR lookalike\n"
+	if stdout.String() != want {
+		t.Errorf("stdout %q, ожидался %q", stdout.String(), want)
+	}
+}
+
+func TestRunSearchZipCorruptArchive(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "broken.gz")
+	good := filepath.Join(dir, "good.log")
+
+	archive := gzipMembers(t, "ERROR before damage\n"+strings.Repeat("filler line\n", 100))
+	// Портим данные после заголовка, чтобы ошибка возникла при распаковке
+	for i := 20; i < len(archive)-8; i++ {
+		archive[i] ^= 0xff
+	}
+	if err := os.WriteFile(corrupt, archive, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(good, []byte("ERROR after\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--search-zip", "ERROR", corrupt, good}, &stdout, &stderr)
+	if code != exitTrouble {
+		t.Errorf("код завершения %d, ожидался %d", code, exitTrouble)
+	}
+	if !strings.Contains(stderr.String(), corrupt) {
+		t.Errorf("нет сообщения об ошибке архива: %q", stderr.String())
+	}
+	if !strings.HasSuffix(stdout.String(), good+":ERROR after\n") {
+		t.Errorf("поиск по остальным файлам должен продолжиться: %q", stdout.String())
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunEncodingIgnoreCase(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "legacy.log")
+	content := "\xce\xd8\xc8\xc1\xca\xc0\x3a\x20\xa8\xe6\xe8\xea\x0a" + "ok\n"
+	if err := os.WriteFile(legacy, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"--encoding=cp1251", "-i", "ошибка", legacy},
+		{"--encoding=cp1251", "-i", "-F", "ошибка: ёжик", legacy},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, &stdout, &stderr); code != exitMatch {
+			t.Fatalf("%v: код завершения %d, stderr: %s", args, code, stderr.String())
+		}
+		if stdout.String() != "ОШИБКА: Ёжик\n" {
+			t.Errorf("%v: stdout %q", args, stdout.String())
+		}
+	}
+}
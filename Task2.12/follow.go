@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+// followPollInterval - как часто проверять, дописан ли файл в режиме --follow
+var followPollInterval = 250 * time.Millisecond
+
+// errFollowTarget - --follow поддерживается только для одного обычного файла
+var errFollowTarget = errors.New("флаг --follow требует ровно один файл")
+
+// followedFile - открытый файл, за которым следит --follow
+type followedFile struct {
+	file   *os.File
+	info   os.FileInfo
+	reader *bufio.Reader
+}
+
+func openFollowed(path string, params *SearchParams) (*followedFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	f := &followedFile{file: file, info: info}
+	if err := f.resetReader(params); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// resetReader начинает чтение файла заново с текущей позиции
+func (f *followedFile) resetReader(params *SearchParams) error {
+	var r io.Reader = f.file
+	if params.encoding != "" {
+		decoded, err := grep.DecodeReader(r, params.encoding)
+		if err != nil {
+			return err
+		}
+		r = decoded
+	}
+	f.reader = bufio.NewReader(r)
+	return nil
+}
+
+// offset возвращает, до какого места файл прочитан: позицию дескриптора
+// за вычетом ещё не разобранного содержимого буфера
+func (f *followedFile) offset() (int64, error) {
+	pos, err := f.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	return pos - int64(f.reader.Buffered()), nil
+}
+
+// followFile ищет по файлу и продолжает читать его по мере дописывания, как tail -f.
+// Новые совпадения с контекстом печатаются сразу. Если файл усечён, поиск начинается
+// с его начала, а при ротации (файл по пути заменён другим) открывается новый файл.
+// Поиск завершается без ошибки при отмене ctx, а также по -q и -m.
+func followFile(ctx context.Context, path string, searcher *grep.Searcher, params *SearchParams, withName bool, out *bufio.Writer) (searchStats, error) {
+	started := time.Now()
+	stats := searchStats{searches: 1}
+
+	f, err := openFollowed(path, params)
+	if err != nil {
+		return stats, err
+	}
+	defer func() { f.file.Close() }()
+
+	var buf bytes.Buffer
+	printer := newPrinter(path, params, withName, &buf)
+	feeder := searcher.NewFeeder(printer.printLine)
+
+	finish := func() (searchStats, error) {
+		stats.addFound(feeder.Stats())
+		stats.elapsed = time.Since(started)
+		if params.countOnly && !params.quiet {
+			buf.WriteString(strconv.Itoa(countValue(stats, params)) + "\n")
+		}
+		printer.finish(&stats)
+		if _, err := out.Write(buf.Bytes()); err != nil {
+			return stats, err
+		}
+		return stats, out.Flush()
+	}
+
+	opts := searcher.Options()
+	terminator := opts.Terminator()
+	pending := "" // начало записи, у которой ещё не дописан разделитель
+
+	// readAvailable передаёт в поиск все записи, дописанные к файлу на этот момент.
+	// Возвращает true, когда поиск завершён по -q или -m
+	readAvailable := func() (bool, error) {
+		for {
+			record, err := f.reader.ReadString(terminator)
+			if err == io.EOF {
+				pending += record
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			record = pending + record
+			pending = ""
+			feeder.Feed(opts.TrimRecord(record), len(record))
+			if feeder.Done() {
+				return true, nil
+			}
+		}
+	}
+
+	for {
+		done, err := readAvailable()
+		if err != nil {
+			return stats, err
+		}
+		if done {
+			return finish()
+		}
+
+		// Дошли до конца файла: выводим найденное и ждём новых данных
+		if _, err := out.Write(buf.Bytes()); err != nil {
+			return stats, err
+		}
+		buf.Reset()
+		if err := out.Flush(); err != nil {
+			return stats, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return finish()
+		case <-time.After(followPollInterval):
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			// Во время ротации файла по пути может временно не быть
+			continue
+		}
+		offset, err := f.offset()
+		if err != nil {
+			return stats, err
+		}
+		switch {
+		case !os.SameFile(info, f.info):
+			// Ротация: по пути уже новый файл, но в старый могли дописать
+			// перед переименованием - дочитываем его до конца
+			reopened, err := openFollowed(path, params)
+			if err != nil {
+				continue
+			}
+			done, err := readAvailable()
+			if err == nil && !done && pending != "" {
+				// Последняя строка старого файла без разделителя уже не допишется
+				feeder.Feed(opts.TrimRecord(pending), len(pending))
+				done = feeder.Done()
+			}
+			f.file.Close()
+			f = reopened
+			if err != nil {
+				return stats, err
+			}
+			if done {
+				return finish()
+			}
+		case info.Size() < offset:
+			// Усечение: файл стал короче прочитанного, читаем его с начала.
+			// Сравнение с размером при прошлой проверке пропустило бы усечение,
+			// после которого файл успели дописать
+			if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+				return stats, err
+			}
+			if err := f.resetReader(params); err != nil {
+				return stats, err
+			}
+		default:
+			continue
+		}
+		// После ротации или усечения номера строк и смещения отсчитываются заново
+		stats.addFound(feeder.Stats())
+		pending = ""
+		feeder = searcher.NewFeeder(printer.printLine)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+// syncBuffer - буфер, безопасный для записи из горутины поиска и чтения из теста
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitForOutput ждёт, пока вывод не станет равен want
+func waitForOutput(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if out.String() == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("вывод %q, ожидался %q", out.String(), want)
+}
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFollowFile(t *testing.T) {
+	interval := followPollInterval
+	followPollInterval = 5 * time.Millisecond
+	defer func() { followPollInterval = interval }()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("ERROR first\nok\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	params := &SearchParams{fixedString: true, lineNumber: true, afterLines: 1}
+	m, err := newMatcher([]string{"ERROR"}, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	type result struct {
+		stats searchStats
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		stats, err := followFile(ctx, path, grep.NewSearcher(m, params.options()), params, false, bufio.NewWriter(out))
+		done <- result{stats, err}
+	}()
+
+	want := "1:ERROR first\n2:ok\n"
+	waitForOutput(t, out, want)
+
+	// Новые строки, в том числе дописанные по частям
+	appendFile(t, path, "noise\nERROR sec")
+	time.Sleep(20 * time.Millisecond)
+	appendFile(t, path, "ond\nafter\n")
+	want += "4:ERROR second\n5:after\n"
+	waitForOutput(t, out, want)
+
+	// Усечение: поиск начинается с начала файла
+	if err := os.WriteFile(path, []byte("ERROR third\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want += "1:ERROR third\n"
+	waitForOutput(t, out, want)
+
+	// Ротация: строка дописана в старый файл прямо перед переименованием,
+	// по пути создан новый
+	appendFile(t, path, "ERROR rotated\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("ok\nERROR fourth\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want += "2:ERROR rotated\n2:ERROR fourth\n"
+	waitForOutput(t, out, want)
+
+	cancel()
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("followFile: %v", res.err)
+	}
+	if res.stats.matchedLines != 5 {
+		t.Errorf("matchedLines = %d, ожидалось 5", res.stats.matchedLines)
+	}
+}
+
+func TestFollowFileStopsOnMaxCount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("ERROR a\nERROR b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	params := &SearchParams{fixedString: true, maxCount: 1}
+	m, err := newMatcher([]string{"ERROR"}, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &syncBuffer{}
+	if _, err := followFile(context.Background(), path, grep.NewSearcher(m, params.options()), params, false, bufio.NewWriter(out)); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "ERROR a\n" {
+		t.Errorf("вывод %q", out.String())
+	}
+}
+
+func TestRunFollowRequiresSingleFile(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--follow", "x", "a", "b"}, &stdout, &stderr); code != exitTrouble {
+		t.Errorf("код завершения %d, ожидался %d", code, exitTrouble)
+	}
+	if !strings.Contains(stderr.String(), errFollowTarget.Error()) {
+		t.Errorf("stderr %q", stderr.String())
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+func TestRunFuzzy(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(logFile, []byte("ERROR one\nEROR two\nWARN three\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-F", "--fuzzy=1", "-o", "-n", "ERROR", logFile}, &stdout, &stderr)
+	if code != exitMatch {
+		t.Fatalf("код завершения %d, stderr: %s", code, stderr.String())
+	}
+	if want := "1:0:ERROR\n2:1:EROR\n"; stdout.String() != want {
+		t.Errorf("вывод %q, ожидалось %q", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	run([]string{"-F", "--fuzzy=1", "--json", "ERROR", logFile}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), `"match":{"text":"EROR"},"start":0,"end":4,"distance":1`) {
+		t.Errorf("в --json нет расстояния вхождения: %s", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := run([]string{"--fuzzy=1", "ERROR", logFile}, &stdout, &stderr); code != exitTrouble {
+		t.Errorf("--fuzzy без -F: код %d, ожидался %d", code, exitTrouble)
+	}
+	if _, err := grep.NewFuzzyMatcher([]string{strings.Repeat("x", 65)}, 1, false); err == nil {
+		t.Error("для слишком длинного паттерна ожидалась ошибка")
+	}
+}
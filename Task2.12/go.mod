@@ -0,0 +1,3 @@
+module github.com/PavelBradnitski/WbTechL2/Task2.12
+
+go 1.24.1
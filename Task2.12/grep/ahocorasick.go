@@ -0,0 +1,177 @@
+package grep
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// acNode - узел автомата Ахо-Корасик
+type acNode struct {
+	next map[rune]int32
+	fail int32
+	// out - индексы паттернов, заканчивающихся в этом узле (с учётом суффиксных ссылок)
+	out []int32
+}
+
+// ahoCorasick - автомат для поиска множества фиксированных строк за один проход по строке.
+// Переходы строятся по рунам, поэтому при игнорировании регистра позиции совпадений
+// считаются по исходной строке, а не по её преобразованной копии.
+type ahoCorasick struct {
+	nodes    []acNode
+	lengths  []int // длины паттернов в рунах
+	maxLen   int
+	hasEmpty bool // пустой паттерн совпадает с любой строкой
+	fold     func(rune) rune
+}
+
+// newAhoCorasick строит автомат по списку паттернов; fold нормализует руны
+// паттернов и текста (например, для игнорирования регистра) и может быть nil
+func newAhoCorasick(patterns []string, fold func(rune) rune) *ahoCorasick {
+	if fold == nil {
+		fold = func(r rune) rune { return r }
+	}
+	ac := &ahoCorasick{
+		nodes:   []acNode{{next: make(map[rune]int32)}},
+		lengths: make([]int, len(patterns)),
+		fold:    fold,
+	}
+
+	for idx, pattern := range patterns {
+		if pattern == "" {
+			ac.hasEmpty = true
+			continue
+		}
+		cur := int32(0)
+		length := 0
+		for _, r := range pattern {
+			r = fold(r)
+			nxt, ok := ac.nodes[cur].next[r]
+			if !ok {
+				ac.nodes = append(ac.nodes, acNode{next: make(map[rune]int32)})
+				nxt = int32(len(ac.nodes) - 1)
+				ac.nodes[cur].next[r] = nxt
+			}
+			cur = nxt
+			length++
+		}
+		ac.nodes[cur].out = append(ac.nodes[cur].out, int32(idx))
+		ac.lengths[idx] = length
+		if length > ac.maxLen {
+			ac.maxLen = length
+		}
+	}
+
+	ac.buildFailLinks()
+	return ac
+}
+
+// buildFailLinks вычисляет суффиксные ссылки обходом в ширину
+func (ac *ahoCorasick) buildFailLinks() {
+	queue := make([]int32, 0, len(ac.nodes))
+	for _, child := range ac.nodes[0].next {
+		ac.nodes[child].fail = 0
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range ac.nodes[cur].next {
+			fail := ac.nodes[cur].fail
+			for {
+				if nxt, ok := ac.nodes[fail].next[r]; ok {
+					ac.nodes[child].fail = nxt
+					break
+				}
+				if fail == 0 {
+					ac.nodes[child].fail = 0
+					break
+				}
+				fail = ac.nodes[fail].fail
+			}
+			failOut := ac.nodes[ac.nodes[child].fail].out
+			if len(failOut) > 0 {
+				ac.nodes[child].out = append(ac.nodes[child].out, failOut...)
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// step выполняет переход автомата по руне
+func (ac *ahoCorasick) step(state int32, r rune) int32 {
+	for {
+		if nxt, ok := ac.nodes[state].next[r]; ok {
+			return nxt
+		}
+		if state == 0 {
+			return 0
+		}
+		state = ac.nodes[state].fail
+	}
+}
+
+// Match сообщает, содержит ли строка хотя бы один из паттернов
+func (ac *ahoCorasick) Match(line string) bool {
+	if ac.hasEmpty {
+		return true
+	}
+	state := int32(0)
+	for _, r := range line {
+		state = ac.step(state, ac.fold(r))
+		if len(ac.nodes[state].out) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FindAll возвращает непересекающиеся вхождения паттернов: из пересекающихся
+// выбирается самое левое, а среди начинающихся в одной позиции - самое длинное
+func (ac *ahoCorasick) FindAll(line string) []Span {
+	if len(ac.nodes) == 1 {
+		return nil
+	}
+
+	// starts - кольцевой буфер байтовых позиций последних maxLen рун
+	starts := make([]int, ac.maxLen)
+	var found []Span
+	state := int32(0)
+	runeIdx := 0
+	for pos := 0; pos < len(line); {
+		r, size := utf8.DecodeRuneInString(line[pos:])
+		starts[runeIdx%ac.maxLen] = pos
+		state = ac.step(state, ac.fold(r))
+		pos += size
+		for _, idx := range ac.nodes[state].out {
+			first := runeIdx - ac.lengths[idx] + 1
+			found = append(found, Span{Start: starts[first%ac.maxLen], End: pos, Pattern: int(idx)})
+		}
+		runeIdx++
+	}
+
+	return selectLeftmostLongest(found)
+}
+
+// selectLeftmostLongest оставляет из пересекающихся вхождений самые левые и длинные
+func selectLeftmostLongest(found []Span) []Span {
+	if len(found) < 2 {
+		return found
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Start != found[j].Start {
+			return found[i].Start < found[j].Start
+		}
+		return found[i].End > found[j].End
+	})
+
+	result := found[:0]
+	lastEnd := -1
+	for _, s := range found {
+		if s.Start >= lastEnd {
+			result = append(result, s)
+			lastEnd = s.End
+		}
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package grep
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAhoCorasickFindAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		fold     func(rune) rune
+		line     string
+		expected []Span
+	}{
+		{
+			name:     "пересекающиеся паттерны: самое левое и длинное",
+			patterns: []string{"he", "she", "hers", "his"},
+			line:     "ushers",
+			expected: []Span{{Start: 1, End: 4, Pattern: 1}},
+		},
+		{
+			name:     "несколько вхождений разных паттернов",
+			patterns: []string{"abc", "d"},
+			line:     "abcdxabc",
+			expected: []Span{{Start: 0, End: 3, Pattern: 0}, {Start: 3, End: 4, Pattern: 1}, {Start: 5, End: 8, Pattern: 0}},
+		},
+		{
+			name:     "позиции в байтах для многобайтовых рун",
+			patterns: []string{"ошибка"},
+			fold:     foldCase,
+			line:     "а ОШИБКА",
+			expected: []Span{{Start: 3, End: 15, Pattern: 0}},
+		},
+		{
+			name:     "вложенный паттерн через суффиксную ссылку",
+			patterns: []string{"abcd", "bc"},
+			line:     "abce",
+			expected: []Span{{Start: 1, End: 3, Pattern: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ac := newAhoCorasick(tt.patterns, tt.fold)
+			got := ac.FindAll(tt.line)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("got %v, want %v", got, tt.expected)
+			}
+			if ac.Match(tt.line) != (len(tt.expected) > 0) {
+				t.Errorf("Match(%q) = %v", tt.line, !(len(tt.expected) > 0))
+			}
+		})
+	}
+}
+
+func TestAhoCorasickEmptyPatternMatchesEverything(t *testing.T) {
+	ac := newAhoCorasick([]string{"x", ""}, nil)
+	if !ac.Match("abc") {
+		t.Error("пустой паттерн должен совпадать с любой строкой")
+	}
+	if got := ac.FindAll("abc"); len(got) != 0 {
+		t.Errorf("пустые вхождения не должны возвращаться, got %v", got)
+	}
+}
@@ -0,0 +1,34 @@
+package grep
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// Режимы обработки двоичных файлов
+const (
+	BinaryFilesBinary       = "binary"        // сообщать только о факте совпадения
+	BinaryFilesText         = "text"          // обрабатывать как текст
+	BinaryFilesWithoutMatch = "without-match" // считать, что совпадений нет
+)
+
+// BinaryCheckSize - размер начального блока, по которому определяется двоичный файл
+const BinaryCheckSize = 32 * 1024
+
+// IsBinary определяет по начальному блоку, похоже ли содержимое на двоичное:
+// есть нулевые байты (если они не разделители записей при nullData) или некорректный UTF-8
+func IsBinary(block []byte, nullData bool) bool {
+	if !nullData && bytes.IndexByte(block, 0) >= 0 {
+		return true
+	}
+	// Блок может обрываться посреди многобайтового символа - отбрасываем неполный хвост
+	for i := len(block) - 1; i >= 0 && i >= len(block)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(block[i]) {
+			if !utf8.FullRune(block[i:]) {
+				block = block[:i]
+			}
+			break
+		}
+	}
+	return !utf8.Valid(block)
+}
@@ -0,0 +1,27 @@
+package grep
+
+import "testing"
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name     string
+		block    []byte
+		nullData bool
+		expected bool
+	}{
+		{name: "обычный текст", block: []byte("hello\nworld\n"), expected: false},
+		{name: "кириллица", block: []byte("привет, мир\n"), expected: false},
+		{name: "нулевой байт", block: []byte("ELF\x00\x01\x02"), expected: true},
+		{name: "нулевой байт при -z", block: []byte("a\x00b\x00"), nullData: true, expected: false},
+		{name: "некорректный UTF-8", block: []byte("abc\xff\xfedef"), expected: true},
+		{name: "символ обрезан концом блока", block: []byte("привет")[:11], expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBinary(tt.block, tt.nullData); got != tt.expected {
+				t.Errorf("IsBinary(%q) = %v, want %v", tt.block, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+)
+
+// Сигнатуры сжатых форматов
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh") // за ним идёт размер блока '1'..'9'
+)
+
+// decompressReader распознаёт сжатый поток по сигнатуре и возвращает reader
+// с распакованным содержимым; несжатые данные возвращаются как есть
+func decompressReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(len(bzip2Magic) + 1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(buffered)
+	case isBzip2(magic):
+		return bzip2.NewReader(buffered), nil
+	default:
+		return buffered, nil
+	}
+}
+
+// isBzip2 проверяет сигнатуру bzip2 вместе с размером блока, чтобы текст,
+// начинающийся с "BZh", не принимался за архив
+func isBzip2(magic []byte) bool {
+	return len(magic) > len(bzip2Magic) && bytes.HasPrefix(magic, bzip2Magic) &&
+		magic[len(bzip2Magic)] >= '1' && magic[len(bzip2Magic)] <= '9'
+}
@@ -0,0 +1,206 @@
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Поддерживаемые кодировки ввода
+const (
+	EncodingAuto    = "auto" // UTF-8, либо кодировка по BOM
+	EncodingUTF8    = "utf-8"
+	EncodingCP1251  = "cp1251"
+	EncodingKOI8R   = "koi8-r"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+)
+
+// Метки порядка байтов
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+)
+
+// cp1251Table - символы Windows-1251 для байтов 0x80-0xFF
+var cp1251Table = [128]rune{
+	0x0402, 0x0403, 0x201A, 0x0453, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x20AC, 0x2030, 0x0409, 0x2039, 0x040A, 0x040C, 0x040B, 0x040F,
+	0x0452, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0xFFFD, 0x2122, 0x0459, 0x203A, 0x045A, 0x045C, 0x045B, 0x045F,
+	0x00A0, 0x040E, 0x045E, 0x0408, 0x00A4, 0x0490, 0x00A6, 0x00A7,
+	0x0401, 0x00A9, 0x0404, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x0407,
+	0x00B0, 0x00B1, 0x0406, 0x0456, 0x0491, 0x00B5, 0x00B6, 0x00B7,
+	0x0451, 0x2116, 0x0454, 0x00BB, 0x0458, 0x0405, 0x0455, 0x0457,
+	0x0410, 0x0411, 0x0412, 0x0413, 0x0414, 0x0415, 0x0416, 0x0417,
+	0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E, 0x041F,
+	0x0420, 0x0421, 0x0422, 0x0423, 0x0424, 0x0425, 0x0426, 0x0427,
+	0x0428, 0x0429, 0x042A, 0x042B, 0x042C, 0x042D, 0x042E, 0x042F,
+	0x0430, 0x0431, 0x0432, 0x0433, 0x0434, 0x0435, 0x0436, 0x0437,
+	0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E, 0x043F,
+	0x0440, 0x0441, 0x0442, 0x0443, 0x0444, 0x0445, 0x0446, 0x0447,
+	0x0448, 0x0449, 0x044A, 0x044B, 0x044C, 0x044D, 0x044E, 0x044F,
+}
+
+// koi8rTable - символы KOI8-R для байтов 0x80-0xFF
+var koi8rTable = [128]rune{
+	0x2500, 0x2502, 0x250C, 0x2510, 0x2514, 0x2518, 0x251C, 0x2524,
+	0x252C, 0x2534, 0x253C, 0x2580, 0x2584, 0x2588, 0x258C, 0x2590,
+	0x2591, 0x2592, 0x2593, 0x2320, 0x25A0, 0x2219, 0x221A, 0x2248,
+	0x2264, 0x2265, 0x00A0, 0x2321, 0x00B0, 0x00B2, 0x00B7, 0x00F7,
+	0x2550, 0x2551, 0x2552, 0x0451, 0x2553, 0x2554, 0x2555, 0x2556,
+	0x2557, 0x2558, 0x2559, 0x255A, 0x255B, 0x255C, 0x255D, 0x255E,
+	0x255F, 0x2560, 0x2561, 0x0401, 0x2562, 0x2563, 0x2564, 0x2565,
+	0x2566, 0x2567, 0x2568, 0x2569, 0x256A, 0x256B, 0x256C, 0x00A9,
+	0x044E, 0x0430, 0x0431, 0x0446, 0x0434, 0x0435, 0x0444, 0x0433,
+	0x0445, 0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E,
+	0x043F, 0x044F, 0x0440, 0x0441, 0x0442, 0x0443, 0x0436, 0x0432,
+	0x044C, 0x044B, 0x0437, 0x0448, 0x044D, 0x0449, 0x0447, 0x044A,
+	0x042E, 0x0410, 0x0411, 0x0426, 0x0414, 0x0415, 0x0424, 0x0413,
+	0x0425, 0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E,
+	0x041F, 0x042F, 0x0420, 0x0421, 0x0422, 0x0423, 0x0416, 0x0412,
+	0x042C, 0x042B, 0x0417, 0x0428, 0x042D, 0x0429, 0x0427, 0x042A,
+}
+
+// ParseEncoding проверяет название кодировки
+func ParseEncoding(encoding string) (string, error) {
+	switch encoding {
+	case EncodingAuto, EncodingUTF8, EncodingCP1251, EncodingKOI8R, EncodingUTF16LE, EncodingUTF16BE:
+		return encoding, nil
+	default:
+		return "", fmt.Errorf("неподдерживаемая кодировка: %q", encoding)
+	}
+}
+
+// DecodeReader возвращает reader, перекодирующий ввод из encoding в UTF-8.
+// BOM учитывается в режиме auto и отбрасывается, если совпадает с кодировкой.
+func DecodeReader(r io.Reader, encoding string) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	bom, err := buffered.Peek(len(utf8BOM))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(bom, utf8BOM) && (encoding == EncodingAuto || encoding == EncodingUTF8):
+		buffered.Discard(len(utf8BOM))
+		encoding = EncodingUTF8
+	case bytes.HasPrefix(bom, utf16LEBOM) && (encoding == EncodingAuto || encoding == EncodingUTF16LE):
+		buffered.Discard(len(utf16LEBOM))
+		encoding = EncodingUTF16LE
+	case bytes.HasPrefix(bom, utf16BEBOM) && (encoding == EncodingAuto || encoding == EncodingUTF16BE):
+		buffered.Discard(len(utf16BEBOM))
+		encoding = EncodingUTF16BE
+	}
+
+	switch encoding {
+	case EncodingCP1251:
+		return newDecodingReader(buffered, singleByteDecoder(&cp1251Table)), nil
+	case EncodingKOI8R:
+		return newDecodingReader(buffered, singleByteDecoder(&koi8rTable)), nil
+	case EncodingUTF16LE:
+		return newDecodingReader(buffered, utf16Decoder(false)), nil
+	case EncodingUTF16BE:
+		return newDecodingReader(buffered, utf16Decoder(true)), nil
+	default:
+		return buffered, nil
+	}
+}
+
+// runeDecoder читает из src один символ
+type runeDecoder func(src *bufio.Reader) (rune, error)
+
+// singleByteDecoder декодирует однобайтовую кодировку, совпадающую с ASCII в младшей половине
+func singleByteDecoder(table *[128]rune) runeDecoder {
+	return func(src *bufio.Reader) (rune, error) {
+		b, err := src.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b < utf8.RuneSelf {
+			return rune(b), nil
+		}
+		return table[b-utf8.RuneSelf], nil
+	}
+}
+
+// utf16Decoder декодирует UTF-16 с учётом суррогатных пар
+func utf16Decoder(bigEndian bool) runeDecoder {
+	readUnit := func(src *bufio.Reader) (rune, error) {
+		first, err := src.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		second, err := src.ReadByte()
+		if err == io.EOF {
+			// Нечётное число байтов: последний байт не образует символа
+			return utf8.RuneError, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if bigEndian {
+			return rune(first)<<8 | rune(second), nil
+		}
+		return rune(second)<<8 | rune(first), nil
+	}
+
+	return func(src *bufio.Reader) (rune, error) {
+		unit, err := readUnit(src)
+		if err != nil || !utf16.IsSurrogate(unit) {
+			return unit, err
+		}
+		// Вторая половина суррогатной пары: если её нет, символ некорректен
+		next, err := src.Peek(2)
+		if err != nil {
+			return utf8.RuneError, nil
+		}
+		low := rune(next[0]) | rune(next[1])<<8
+		if bigEndian {
+			low = rune(next[0])<<8 | rune(next[1])
+		}
+		decoded := utf16.DecodeRune(unit, low)
+		if decoded == utf8.RuneError {
+			return decoded, nil
+		}
+		src.Discard(2)
+		return decoded, nil
+	}
+}
+
+// decodingReader перекодирует поток в UTF-8 посимвольно
+type decodingReader struct {
+	src    *bufio.Reader
+	decode runeDecoder
+	buf    []byte // перекодированные, но ещё не прочитанные байты
+	err    error
+}
+
+func newDecodingReader(src *bufio.Reader, decode runeDecoder) *decodingReader {
+	return &decodingReader{src: src, decode: decode}
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	for len(d.buf) < len(p) && d.err == nil {
+		r, err := d.decode(d.src)
+		if err != nil {
+			d.err = err
+			break
+		}
+		d.buf = utf8.AppendRune(d.buf, r)
+	}
+
+	n := copy(p, d.buf)
+	d.buf = append(d.buf[:0], d.buf[n:]...)
+	// Ошибка отдаётся один раз: при --follow файл дописывается после io.EOF
+	if n == 0 && d.err != nil {
+		err := d.err
+		d.err = nil
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,77 @@
+package grep
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecodeReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		input    string
+		expected string
+	}{
+		{
+			name:     "cp1251",
+			encoding: EncodingCP1251,
+			input:    "\xce\xd8\xc8\xc1\xca\xc0\x3a\x20\xa8\xe6\xe8\xea\x0a",
+			expected: "ОШИБКА: Ёжик\n",
+		},
+		{
+			name:     "koi8-r",
+			encoding: EncodingKOI8R,
+			input:    "\xef\xfb\xe9\xe2\xeb\xe1\x3a\x20\xb3\xd6\xc9\xcb\x0a",
+			expected: "ОШИБКА: Ёжик\n",
+		},
+		{
+			name:     "auto с BOM UTF-16LE и суррогатной парой",
+			encoding: EncodingAuto,
+			input: "\xff\xfe\x1e\x04\x28\x04\x18\x04\x11\x04\x1a\x04\x10\x04\x3a\x00\x20\x00\x01\x04\x36\x04" +
+				"\x38\x04\x3a\x04\x20\x00\x3d\xd8\x00\xde\x0a\x00",
+			expected: "ОШИБКА: Ёжик 😀\n",
+		},
+		{
+			name:     "utf-16be без BOM",
+			encoding: EncodingUTF16BE,
+			input: "\x04\x1e\x04\x28\x04\x18\x04\x11\x04\x1a\x04\x10\x00\x3a\x00\x20\x04\x01\x04\x36\x04\x38" +
+				"\x04\x3a\x00\x20\xd8\x3d\xde\x00\x00\x0a",
+			expected: "ОШИБКА: Ёжик 😀\n",
+		},
+		{
+			name:     "auto отбрасывает BOM UTF-8",
+			encoding: EncodingAuto,
+			input:    "\xef\xbb\xbfтекст\n",
+			expected: "текст\n",
+		},
+		{
+			name:     "auto без BOM оставляет ввод как есть",
+			encoding: EncodingAuto,
+			input:    "plain text\n",
+			expected: "plain text\n",
+		},
+		{
+			name:     "неполная суррогатная пара и нечётный байт",
+			encoding: EncodingUTF16LE,
+			input:    "\x3d\xd8\x41\x00\x42",
+			expected: "�A�",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := DecodeReader(strings.NewReader(tt.input), tt.encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package grep_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+func ExampleSearcher_Search() {
+	opts := grep.Options{IgnoreCase: true, After: 1}
+	m, err := grep.NewMatcher([]string{`error \d+`}, opts)
+	if err != nil {
+		panic(err)
+	}
+
+	input := "ok\nERROR 42: disk full\nretrying\nok\n"
+	s := grep.NewSearcher(m, opts)
+	_, err = s.Search(context.Background(), strings.NewReader(input), func(m grep.Match) error {
+		fmt.Println(m.LineNumber, m.Context, m.Line)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// 2 false ERROR 42: disk full
+	// 3 true retrying
+}
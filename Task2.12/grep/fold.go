@@ -0,0 +1,90 @@
+package grep
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// foldTableSize - для рун меньше этого значения свёртка берётся из таблицы:
+// латиница, греческий и кириллица
+const foldTableSize = 0x600
+
+// foldTable - заранее вычисленная свёртка регистра для частых рун
+var foldTable = func() (table [foldTableSize]rune) {
+	for r := range table {
+		table[r] = simpleFold(rune(r))
+	}
+	return table
+}()
+
+// simpleFold возвращает наименьшую руну из орбиты unicode.SimpleFold: все руны,
+// равные r без учёта регистра, сворачиваются в одну. Это та же эквивалентность,
+// что у (?i) в regexp: K, k и знак кельвина совпадают, а İ и i - нет.
+func simpleFold(r rune) rune {
+	least := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < least {
+			least = f
+		}
+	}
+	return least
+}
+
+// foldCase - нормализация рун при игнорировании регистра, без выделения памяти
+func foldCase(r rune) rune {
+	if r >= 0 && r < foldTableSize {
+		return foldTable[r]
+	}
+	return simpleFold(r)
+}
+
+// Буквы, которые --ignore-yo считает одинаковыми
+const (
+	smallYo = 'ё'
+	smallYe = 'е'
+	capYo   = 'Ё'
+	capYe   = 'Е'
+)
+
+// foldYo заменяет ё на е с сохранением регистра
+func foldYo(r rune) rune {
+	switch r {
+	case smallYo:
+		return smallYe
+	case capYo:
+		return capYe
+	}
+	return r
+}
+
+// foldFunc собирает нормализацию рун для поиска фиксированных строк; nil - без нормализации
+func foldFunc(ignoreCase, ignoreYo bool) func(rune) rune {
+	switch {
+	case ignoreCase && ignoreYo:
+		return func(r rune) rune { return foldCase(foldYo(r)) }
+	case ignoreCase:
+		return foldCase
+	case ignoreYo:
+		return foldYo
+	}
+	return nil
+}
+
+// replaceYo заменяет в строке ё на е. Буквы занимают в UTF-8 одинаковое число байтов,
+// поэтому позиции вхождений в результате совпадают с позициями в исходной строке.
+// Строка без ё возвращается как есть, без выделения памяти.
+func replaceYo(s string) string {
+	if !strings.ContainsRune(s, smallYo) && !strings.ContainsRune(s, capYo) {
+		return s
+	}
+	b := []byte(s)
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if yo := foldYo(r); yo != r {
+			utf8.EncodeRune(b[i:], yo)
+		}
+		i += size
+	}
+	return string(b)
+}
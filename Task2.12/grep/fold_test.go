@@ -0,0 +1,93 @@
+package grep
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFoldCaseMatchesRegexp(t *testing.T) {
+	tests := []struct {
+		a, b rune
+		same bool
+	}{
+		{'k', 'K', true},
+		{'k', 'K', true}, // знак кельвина
+		{'ß', 'ẞ', true},
+		{'σ', 'ς', true},
+		{'Σ', 'ς', true},
+		{'ё', 'Ё', true},
+		{'i', 'İ', false}, // турецкая İ не сворачивается в i простой свёрткой
+		{'ı', 'I', false},
+		{'ё', 'е', false},
+	}
+
+	for _, tt := range tests {
+		if got := foldCase(tt.a) == foldCase(tt.b); got != tt.same {
+			t.Errorf("foldCase(%q) == foldCase(%q): %v, ожидалось %v", tt.a, tt.b, got, tt.same)
+		}
+		// Фиксированные строки и (?i) в regexp должны давать одинаковый результат
+		re := regexp.MustCompile("(?i)^" + regexp.QuoteMeta(string(tt.a)) + "$")
+		if re.MatchString(string(tt.b)) != tt.same {
+			t.Errorf("(?i) для %q и %q расходится с foldCase", tt.a, tt.b)
+		}
+	}
+}
+
+func TestIgnoreYo(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		opts    Options
+		line    string
+		want    bool
+	}{
+		{"строка с ё, паттерн с е", "елка", Options{FixedStrings: true, IgnoreYo: true}, "Новогодняя ёлка", true},
+		{"без --ignore-yo буквы различаются", "елка", Options{FixedStrings: true}, "ёлка", false},
+		{"регистр и ё вместе", "ЁЛКА", Options{FixedStrings: true, IgnoreCase: true, IgnoreYo: true}, "елка", true},
+		{"регулярное выражение", `зел[её]н`, Options{IgnoreYo: true}, "ЗЕЛЁНЫЙ", false},
+		{"регулярное выражение без учёта регистра", `зел[е]н`, Options{IgnoreYo: true, IgnoreCase: true}, "ЗЕЛЁНЫЙ", true},
+		{"отрицание класса исключает обе буквы", `зел[^ё]н`, Options{IgnoreYo: true}, "зеленый", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMatcher([]string{tt.pattern}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Match(tt.line); got != tt.want {
+				t.Errorf("Match(%q) = %v, ожидалось %v", tt.line, got, tt.want)
+			}
+		})
+	}
+
+	// Позиции вхождений относятся к исходной строке
+	m, err := NewMatcher([]string{"ел"}, Options{IgnoreYo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := "ёлка, ель"
+	var got []string
+	for _, sp := range m.FindAll(line) {
+		got = append(got, line[sp.Start:sp.End])
+	}
+	if len(got) != 2 || got[0] != "ёл" || got[1] != "ел" {
+		t.Errorf("вхождения %q в %q, ожидались [ёл ел]", got, line)
+	}
+}
+
+func TestIgnoreCaseDoesNotAllocate(t *testing.T) {
+	m, err := NewMatcher([]string{"ОШИБКА", "timeout"}, Options{FixedStrings: true, IgnoreCase: true, IgnoreYo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := "2024-01-01 Ёжик: Connection TIMEOUT после ошибка"
+	allocs := testing.AllocsPerRun(100, func() {
+		if !m.Match(line) {
+			t.Fatal("ожидалось совпадение")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Match выделяет память: %v раз на строку", allocs)
+	}
+}
@@ -0,0 +1,225 @@
+package grep
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// maxFuzzyPatternLen - предел длины паттерна для нечёткого поиска: битовый вектор помещается в uint64
+const maxFuzzyPatternLen = 64
+
+// fuzzyPattern - паттерн для приближённого поиска алгоритмом Майерса
+type fuzzyPattern struct {
+	runes []rune
+	ascii [utf8.RuneSelf]uint64 // маски позиций символов паттерна для ASCII
+	other map[rune]uint64       // маски для остальных символов
+	last  uint64                // бит последней позиции паттерна
+	mask  uint64                // биты всех позиций паттерна
+}
+
+func newFuzzyPattern(pattern string, fold func(rune) rune) (*fuzzyPattern, error) {
+	p := &fuzzyPattern{other: make(map[rune]uint64)}
+	for _, r := range pattern {
+		p.runes = append(p.runes, fold(r))
+	}
+	if len(p.runes) == 0 {
+		return nil, fmt.Errorf("пустой паттерн нельзя искать нечётко")
+	}
+	if len(p.runes) > maxFuzzyPatternLen {
+		return nil, fmt.Errorf("паттерн %q длиннее %d символов и не поддерживается нечётким поиском", pattern, maxFuzzyPatternLen)
+	}
+
+	for i, r := range p.runes {
+		if r < utf8.RuneSelf {
+			p.ascii[r] |= 1 << i
+		} else {
+			p.other[r] |= 1 << i
+		}
+	}
+	p.last = 1 << (len(p.runes) - 1)
+	p.mask = p.last | (p.last - 1)
+	return p, nil
+}
+
+func (p *fuzzyPattern) peq(r rune) uint64 {
+	if r < utf8.RuneSelf {
+		return p.ascii[r]
+	}
+	return p.other[r]
+}
+
+// myersState - битовые векторы вертикальных разностей столбца матрицы расстояний
+type myersState struct {
+	pv, mv uint64
+	score  int // наименьшее расстояние от паттерна до подстроки, оканчивающейся текущим символом
+}
+
+func (p *fuzzyPattern) start() myersState {
+	return myersState{pv: p.mask, score: len(p.runes)}
+}
+
+// step пересчитывает столбец матрицы для очередного символа текста (Myers, 1999)
+func (p *fuzzyPattern) step(st *myersState, r rune) {
+	eq := p.peq(r)
+	xv := eq | st.mv
+	xh := (((eq & st.pv) + st.pv) ^ st.pv) | eq
+	ph := st.mv | ^(xh | st.pv)
+	mh := st.pv & xh
+	if ph&p.last != 0 {
+		st.score++
+	} else if mh&p.last != 0 {
+		st.score--
+	}
+	ph <<= 1
+	mh <<= 1
+	st.pv = (mh | ^(xv | ph)) & p.mask
+	st.mv = ph & xv & p.mask
+}
+
+// fuzzyMatcher ищет подстроки, отличающиеся от одного из паттернов не более чем
+// на maxDist правок (вставка, удаление, замена символа)
+type fuzzyMatcher struct {
+	patterns []*fuzzyPattern
+	maxDist  int
+	fold     func(rune) rune
+}
+
+// NewFuzzyMatcher возвращает нечёткий поиск фиксированных строк с расстоянием
+// Левенштейна не больше maxDist; паттерны ограничены maxFuzzyPatternLen символами
+func NewFuzzyMatcher(patterns []string, maxDist int, ignoreCase bool) (Matcher, error) {
+	return newFuzzyMatcher(patterns, maxDist, foldFunc(ignoreCase, false))
+}
+
+func newFuzzyMatcher(patterns []string, maxDist int, fold func(rune) rune) (*fuzzyMatcher, error) {
+	if fold == nil {
+		fold = func(r rune) rune { return r }
+	}
+	m := &fuzzyMatcher{maxDist: maxDist, fold: fold}
+	for _, pattern := range patterns {
+		p, err := newFuzzyPattern(pattern, fold)
+		if err != nil {
+			return nil, err
+		}
+		m.patterns = append(m.patterns, p)
+	}
+	return m, nil
+}
+
+func (m *fuzzyMatcher) Match(line string) bool {
+	for _, p := range m.patterns {
+		// Паттерн целиком удаляется не более чем за maxDist правок
+		if len(p.runes) <= m.maxDist {
+			return true
+		}
+		st := p.start()
+		for _, r := range line {
+			p.step(&st, m.fold(r))
+			if st.score <= m.maxDist {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *fuzzyMatcher) FindAll(line string) []Span {
+	runes := make([]rune, 0, len(line))
+	offsets := make([]int, 0, len(line)+1)
+	for pos, r := range line {
+		runes = append(runes, m.fold(r))
+		offsets = append(offsets, pos)
+	}
+	offsets = append(offsets, len(line))
+
+	var found []Span
+	for idx, p := range m.patterns {
+		for _, sp := range m.findPattern(p, runes) {
+			found = append(found, Span{
+				Start:    offsets[sp.Start],
+				End:      offsets[sp.End],
+				Pattern:  idx,
+				Distance: sp.Distance,
+			})
+		}
+	}
+	return selectLeftmostLongest(found)
+}
+
+// findPattern возвращает вхождения одного паттерна в позициях рун. Вхождение
+// заканчивается в локальном минимуме расстояния: из подряд идущих концов с
+// одинаковым расстоянием берётся последний, а начало восстанавливается обратным
+// проходом. Пересечения вхождений убирает selectLeftmostLongest.
+func (m *fuzzyMatcher) findPattern(p *fuzzyPattern, text []rune) []Span {
+	var found []Span
+	st := p.start()
+	best, bestEnd := -1, -1
+	// rising - расстояние растёт после выданного вхождения: новый кандидат
+	// появится только когда оно снова пойдёт вниз
+	rising := false
+
+	flush := func() {
+		if bestEnd < 0 {
+			return
+		}
+		start := m.matchStart(p, text, bestEnd, best)
+		if start < bestEnd {
+			found = append(found, Span{Start: start, End: bestEnd, Distance: best})
+		}
+		best, bestEnd = -1, -1
+	}
+
+	prev := m.maxDist + 1
+	for j, r := range text {
+		p.step(&st, r)
+		switch {
+		case st.score > m.maxDist:
+			flush()
+			rising = false
+		case bestEnd >= 0 && st.score > best:
+			// соседние вхождения внутри одной серии подходящих концов
+			flush()
+			rising = true
+		case rising && st.score >= prev:
+			// склон после вхождения - не минимум
+		default:
+			best, bestEnd = st.score, j+1
+			rising = false
+		}
+		prev = st.score
+	}
+	flush()
+	return found
+}
+
+// matchStart находит начало самой длинной подстроки text[start:end] с расстоянием dist
+// до паттерна: динамика по перевёрнутым паттерну и тексту. Подстрока длиннее
+// len(паттерна)+maxDist не может уложиться в допустимое число правок.
+func (m *fuzzyMatcher) matchStart(p *fuzzyPattern, text []rune, end, dist int) int {
+	n := len(p.runes)
+	maxLen := min(n+m.maxDist, end)
+
+	// column[i] - расстояние между последними i символами паттерна и текущим суффиксом текста
+	column := make([]int, n+1)
+	for i := range column {
+		column[i] = i
+	}
+	start := end
+	for l := 1; l <= maxLen; l++ {
+		r := text[end-l]
+		diag := column[0]
+		column[0] = l
+		for i := 1; i <= n; i++ {
+			cost := 1
+			if p.runes[n-i] == r {
+				cost = 0
+			}
+			next := min(diag+cost, column[i]+1, column[i-1]+1)
+			diag = column[i]
+			column[i] = next
+		}
+		if column[n] <= dist {
+			start = end - l
+		}
+	}
+	return start
+}
@@ -0,0 +1,102 @@
+package grep
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// naiveDistance - наименьшее расстояние Левенштейна от паттерна до подстроки text,
+// оканчивающейся в каждой позиции: эталон для проверки алгоритма Майерса
+func naiveDistance(pattern, text []rune) []int {
+	prev := make([]int, len(pattern)+1)
+	for i := range prev {
+		prev[i] = i
+	}
+	scores := make([]int, len(text))
+	for j, r := range text {
+		cur := make([]int, len(pattern)+1)
+		for i := 1; i <= len(pattern); i++ {
+			cost := 1
+			if pattern[i-1] == r {
+				cost = 0
+			}
+			cur[i] = min(prev[i-1]+cost, prev[i]+1, cur[i-1]+1)
+		}
+		scores[j] = cur[len(pattern)]
+		prev = cur
+	}
+	return scores
+}
+
+func TestFuzzyPatternMatchesNaiveDistance(t *testing.T) {
+	cases := []struct{ pattern, text string }{
+		{"ERROR", "ERRROR in EROR and ERORR"},
+		{"timeout", "connection tiemout after tmeout"},
+		{"ошибка", "ощибка и ошибкa и шибка"},
+		{"a", "bbb a bab"},
+		{strings.Repeat("ab", 32), strings.Repeat("ab", 40) + "x" + strings.Repeat("ba", 30)},
+	}
+	for _, tc := range cases {
+		p, err := newFuzzyPattern(tc.pattern, func(r rune) rune { return r })
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := naiveDistance([]rune(tc.pattern), []rune(tc.text))
+		st := p.start()
+		for j, r := range []rune(tc.text) {
+			p.step(&st, r)
+			if st.score != want[j] {
+				t.Fatalf("%q в %q: позиция %d, расстояние %d, ожидалось %d", tc.pattern, tc.text, j, st.score, want[j])
+			}
+		}
+	}
+}
+
+func TestFuzzyMatcherFindAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		maxDist  int
+		fold     func(rune) rune
+		line     string
+		want     []string
+		dists    []int
+	}{
+		{"Точное совпадение", []string{"ERROR"}, 1, nil, "got ERROR here", []string{"ERROR"}, []int{0}},
+		{"Пропущенная буква", []string{"ERROR"}, 1, nil, "got EROR here", []string{"EROR"}, []int{1}},
+		{"Лишняя буква берётся целиком", []string{"ERROR"}, 1, nil, "ERRROR", []string{"ERRROR"}, []int{1}},
+		{"Замена", []string{"timeout"}, 1, nil, "request timaout", []string{"timaout"}, []int{1}},
+		{"Перестановка - две правки", []string{"timeout"}, 1, nil, "request timeuot", nil, nil},
+		{"Без учёта регистра", []string{"ошибка"}, 1, foldCase, "ОШИБКА и Ощибка", []string{"ОШИБКА", "Ощибка"}, []int{0, 1}},
+		{"Несколько паттернов", []string{"error", "warn"}, 1, nil, "eror then wran", []string{"eror"}, []int{1}},
+		{"Слишком далеко", []string{"ERROR"}, 1, nil, "ERxxOR", nil, nil},
+		{"Соседние вхождения", []string{"ab"}, 1, nil, "ab ab", []string{"ab", "ab"}, []int{0, 0}},
+		{"Вхождения подряд", []string{"abc"}, 1, nil, "abcabc", []string{"abc", "abc"}, []int{0, 0}},
+		{"Неточные вхождения подряд", []string{"ERROR"}, 1, nil, "EROR ERRORR", []string{"EROR", "ERROR"}, []int{1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newFuzzyMatcher(tt.patterns, tt.maxDist, tt.fold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			var dists []int
+			for _, sp := range m.FindAll(tt.line) {
+				got = append(got, tt.line[sp.Start:sp.End])
+				dists = append(dists, sp.Distance)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("FindAll(%q) = %q, ожидалось %q", tt.line, got, tt.want)
+			}
+			if len(tt.dists) > 0 && !reflect.DeepEqual(dists, tt.dists) {
+				t.Errorf("расстояния %v, ожидалось %v", dists, tt.dists)
+			}
+			if m.Match(tt.line) != (len(tt.want) > 0) {
+				t.Errorf("Match(%q) = %v", tt.line, !(len(tt.want) > 0))
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package grep
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Span - позиция вхождения паттерна в строке, в байтах
+type Span struct {
+	Start   int
+	End     int
+	Pattern int // индекс паттерна, давшего совпадение
+	// Distance - число правок относительно паттерна при нечётком поиске, для точных совпадений 0
+	Distance int
+	// Groups - позиции групп паттерна для подстановки в замену: пары start, end,
+	// первая пара - всё вхождение; заполняется только для регулярных выражений
+	Groups []int
+}
+
+// Matcher ищет вхождения паттернов в строке
+type Matcher interface {
+	// Match сообщает, есть ли в строке хотя бы одно вхождение
+	Match(line string) bool
+	// FindAll возвращает непустые непересекающиеся вхождения слева направо
+	FindAll(line string) []Span
+}
+
+// Expander подставляет группы вхождения в шаблон замены; его реализуют
+// регулярные выражения, для фиксированных строк шаблон вставляется как есть
+type Expander interface {
+	Expand(template, line string, sp Span) string
+}
+
+// NewMatcher выбирает реализацию поиска по параметрам: одиночная фиксированная строка,
+// автомат Ахо-Корасик для множества фиксированных строк, нечёткий поиск
+// или регулярное выражение
+func NewMatcher(patterns []string, opts Options) (Matcher, error) {
+	if len(patterns) == 0 {
+		// Как в GNU grep: пустой список паттернов (например, пустой файл -f)
+		// не совпадает ни с одной строкой
+		return emptyMatcher{}, nil
+	}
+	if opts.FixedStrings {
+		fold := foldFunc(opts.IgnoreCase, opts.IgnoreYo)
+		if opts.Fuzzy > 0 {
+			return newFuzzyMatcher(patterns, opts.Fuzzy, fold)
+		}
+		if len(patterns) == 1 && fold == nil {
+			return NewLiteralMatcher(patterns[0]), nil
+		}
+		return newAhoCorasick(patterns, fold), nil
+	}
+	if opts.IgnoreYo {
+		// Паттерн нормализуется так же, как строки, поэтому и классы вроде [^ё] работают
+		yoPatterns := make([]string, len(patterns))
+		for i, pattern := range patterns {
+			yoPatterns[i] = replaceYo(pattern)
+		}
+		patterns = yoPatterns
+	}
+	m, err := NewRegexMatcher(patterns, opts.IgnoreCase)
+	if err != nil {
+		return nil, err
+	}
+	m.captures = opts.Replace
+	m.ignoreYo = opts.IgnoreYo
+	return m, nil
+}
+
+// emptyMatcher - поиск без паттернов, не находит ничего
+type emptyMatcher struct{}
+
+func (emptyMatcher) Match(string) bool { return false }
+
+func (emptyMatcher) FindAll(string) []Span { return nil }
+
+// literalMatcher - поиск одной фиксированной строки с учётом регистра
+type literalMatcher struct {
+	pattern string
+}
+
+// NewLiteralMatcher возвращает поиск одной фиксированной строки с учётом регистра
+func NewLiteralMatcher(pattern string) Matcher {
+	return literalMatcher{pattern: pattern}
+}
+
+func (m literalMatcher) Match(line string) bool {
+	return strings.Contains(line, m.pattern)
+}
+
+func (m literalMatcher) FindAll(line string) []Span {
+	if m.pattern == "" {
+		return nil
+	}
+	var spans []Span
+	offset := 0
+	for {
+		idx := strings.Index(line[offset:], m.pattern)
+		if idx < 0 {
+			return spans
+		}
+		start := offset + idx
+		offset = start + len(m.pattern)
+		spans = append(spans, Span{Start: start, End: offset})
+	}
+}
+
+// NewMultiMatcher возвращает поиск множества фиксированных строк за один проход
+func NewMultiMatcher(patterns []string, ignoreCase bool) Matcher {
+	return newAhoCorasick(patterns, foldFunc(ignoreCase, false))
+}
+
+// RegexMatcher - поиск по регулярному выражению. Несколько паттернов объединяются
+// в одну альтернативу, где каждый обёрнут в группу, чтобы определить, какой из них совпал.
+type RegexMatcher struct {
+	re     *regexp.Regexp
+	groups []int            // номер группы-обёртки для каждого паттерна; пусто для одного паттерна
+	subs   []*regexp.Regexp // паттерны по отдельности: по ним разбираются ссылки на группы в замене
+	// captures - сохранять позиции групп во вхождениях для замены
+	captures bool
+	// ignoreYo - заменять в строках ё на е перед поиском
+	ignoreYo bool
+}
+
+// NewRegexMatcher компилирует паттерны в одно регулярное выражение
+func NewRegexMatcher(patterns []string, ignoreCase bool) (*RegexMatcher, error) {
+	if len(patterns) == 0 {
+		return nil, errors.New("не задан ни один паттерн")
+	}
+	prefix := ""
+	if ignoreCase {
+		prefix = "(?i)"
+	}
+
+	if len(patterns) == 1 {
+		re, err := regexp.Compile(prefix + patterns[0])
+		if err != nil {
+			return nil, fmt.Errorf("некорректный паттерн %q: %w", patterns[0], err)
+		}
+		return &RegexMatcher{re: re, subs: []*regexp.Regexp{re}}, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	groups := make([]int, len(patterns))
+	subs := make([]*regexp.Regexp, len(patterns))
+	group := 1
+	for idx, pattern := range patterns {
+		// Компилируем паттерн отдельно, чтобы сообщить, какой из них некорректен,
+		// и узнать число его групп для вычисления номеров групп-обёрток
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный паттерн %q: %w", pattern, err)
+		}
+		if idx > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("(" + pattern + ")")
+		groups[idx] = group
+		subs[idx] = re
+		group += 1 + re.NumSubexp()
+	}
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("некорректный набор паттернов: %w", err)
+	}
+	return &RegexMatcher{re: re, groups: groups, subs: subs}, nil
+}
+
+// WithCaptures включает сохранение позиций групп в Span.Groups для Expand
+func (m *RegexMatcher) WithCaptures() *RegexMatcher {
+	m.captures = true
+	return m
+}
+
+func (m *RegexMatcher) Match(line string) bool {
+	if m.ignoreYo {
+		line = replaceYo(line)
+	}
+	return m.re.MatchString(line)
+}
+
+func (m *RegexMatcher) FindAll(line string) []Span {
+	if m.ignoreYo {
+		line = replaceYo(line)
+	}
+	if len(m.groups) == 0 && !m.captures {
+		locs := m.re.FindAllStringIndex(line, -1)
+		spans := make([]Span, 0, len(locs))
+		for _, loc := range locs {
+			if loc[0] != loc[1] {
+				spans = append(spans, Span{Start: loc[0], End: loc[1]})
+			}
+		}
+		return spans
+	}
+
+	locs := m.re.FindAllStringSubmatchIndex(line, -1)
+	spans := make([]Span, 0, len(locs))
+	for _, loc := range locs {
+		if loc[0] == loc[1] {
+			continue
+		}
+		s := Span{Start: loc[0], End: loc[1]}
+		for idx, group := range m.groups {
+			if loc[2*group] >= 0 {
+				s.Pattern = idx
+				break
+			}
+		}
+		if m.captures {
+			s.Groups = m.patternGroups(loc, s.Pattern)
+		}
+		spans = append(spans, s)
+	}
+	return spans
+}
+
+// patternGroups выделяет из позиций групп объединённого выражения группы паттерна idx,
+// нумеруя их так же, как в самом паттерне
+func (m *RegexMatcher) patternGroups(loc []int, idx int) []int {
+	if len(m.groups) == 0 {
+		return loc
+	}
+	first := m.groups[idx] + 1
+	return append([]int{loc[0], loc[1]}, loc[2*first:2*(first+m.subs[idx].NumSubexp())]...)
+}
+
+// Expand подставляет в шаблон группы вхождения: $1, ${1}, $name, ${name}, $$ - знак доллара.
+// Позиции групп есть только у вхождений, найденных после WithCaptures.
+func (m *RegexMatcher) Expand(template, line string, sp Span) string {
+	return string(m.subs[sp.Pattern].ExpandString(nil, template, line, sp.Groups))
+}
+
+// Replacements возвращает текст замены по шаблону для каждого вхождения
+func Replacements(m Matcher, template, line string, spans []Span) []string {
+	result := make([]string, len(spans))
+	e, ok := m.(Expander)
+	for i, sp := range spans {
+		if ok {
+			result[i] = e.Expand(template, line, sp)
+		} else {
+			result[i] = template
+		}
+	}
+	return result
+}
@@ -0,0 +1,325 @@
+// Package grep ищет строки по паттернам в потоке: фиксированные строки, множества
+// строк, регулярные выражения и нечёткий поиск, с контекстом вокруг совпадений.
+// На пакете построена утилита grep; Options повторяют её флаги.
+package grep
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+)
+
+// ctxCheckInterval - через сколько строк проверять отмену поиска
+const ctxCheckInterval = 1024
+
+// Options - параметры поиска; в комментариях указаны соответствующие флаги grep
+type Options struct {
+	IgnoreCase   bool // -i: простая свёртка регистра Unicode
+	IgnoreYo     bool // --ignore-yo: считать ё и е одной буквой
+	FixedStrings bool // -F: паттерны - фиксированные строки, а не регулярные выражения
+	Fuzzy        int  // --fuzzy: допустимое число правок при FixedStrings; 0 - точный поиск
+	InvertMatch  bool // -v: выбирать строки без вхождений
+	Before       int  // -B: строк контекста до выбранной строки
+	After        int  // -A: строк контекста после выбранной строки
+	MaxCount     int  // -m: максимум выбранных строк; 0 или меньше - без ограничения
+	// CountOnly (-c) - только подсчитать выбранные строки, не передавая их обработчику
+	CountOnly bool
+	// Quiet (-q) - остановиться на первой выбранной строке, не передавая её обработчику
+	Quiet bool
+	// BinaryFiles - обработка двоичного ввода (--binary-files); пустая строка - BinaryFilesBinary
+	BinaryFiles string
+	NullData    bool   // -z: записи разделяются нулевым байтом, а не переводом строки
+	Decompress  bool   // --search-zip: распаковывать ввод в gzip и bzip2
+	Encoding    string // --encoding: кодировка ввода; пустая строка - без перекодирования
+	// Submatches - искать вхождения в выбранных строках (Match.Submatches);
+	// без них поиск быстрее, если нужны только сами строки
+	Submatches bool
+	// Replace (--replace) - вычислять Match.Replacements по шаблону Replacement
+	Replace     bool
+	Replacement string
+}
+
+// Terminator возвращает разделитель записей: перевод строки или нулевой байт при NullData
+func (o *Options) Terminator() byte {
+	if o.NullData {
+		return 0
+	}
+	return '\n'
+}
+
+// TrimRecord отрезает от прочитанной записи разделитель, а для строк - и '\r'
+func (o *Options) TrimRecord(record string) string {
+	record = strings.TrimSuffix(record, string(o.Terminator()))
+	if !o.NullData {
+		record = strings.TrimSuffix(record, "\r")
+	}
+	return record
+}
+
+// Match - строка результата: выбранная строка или строка контекста вокруг неё
+type Match struct {
+	LineNumber int
+	Offset     int64  // смещение начала строки в байтах от начала ввода
+	Line       string // строка без разделителя
+	Context    bool   // строка контекста, а не выбранная
+	// Submatches - вхождения в выбранной строке при Options.Submatches; при InvertMatch пусто
+	Submatches []Span
+	// Replacements - тексты замены для Submatches при Options.Replace
+	Replacements []string
+}
+
+// Stats - итоги поиска по одному вводу
+type Stats struct {
+	BytesSearched int64
+	MatchedLines  int
+	Matches       int  // число вхождений; считается только при Options.Submatches
+	Binary        bool // ввод двоичный: строки не передавались обработчику
+}
+
+// Searcher ищет строки по Matcher с параметрами Options. Searcher не хранит
+// состояния поиска, поэтому один экземпляр можно использовать из нескольких горутин.
+type Searcher struct {
+	m    Matcher
+	opts Options
+}
+
+// NewSearcher создаёт поиск; контекст при CountOnly и Quiet не выводится
+func NewSearcher(m Matcher, opts Options) *Searcher {
+	if opts.CountOnly || opts.Quiet {
+		opts.Before, opts.After = 0, 0
+	}
+	if opts.BinaryFiles == "" {
+		opts.BinaryFiles = BinaryFilesBinary
+	}
+	if opts.Replace {
+		opts.Submatches = true
+	}
+	return &Searcher{m: m, opts: opts}
+}
+
+// Matcher возвращает паттерны поиска
+func (s *Searcher) Matcher() Matcher {
+	return s.m
+}
+
+// Options возвращает параметры поиска
+func (s *Searcher) Options() Options {
+	return s.opts
+}
+
+// errStopIteration останавливает Search, когда итератор больше не нужен
+var errStopIteration = errors.New("итерация прекращена")
+
+// Search читает r построчно и передаёт в fn выбранные строки и строки контекста
+// по мере их появления. Ошибка fn прекращает поиск и возвращается из Search;
+// отмена ctx тоже прекращает поиск.
+func (s *Searcher) Search(ctx context.Context, r io.Reader, fn func(Match) error) (Stats, error) {
+	var stats Stats
+
+	// Сжатый ввод распаковывается на лету: номера строк, смещения
+	// и контекст относятся к распакованному содержимому
+	if s.opts.Decompress {
+		decompressed, err := decompressReader(r)
+		if err != nil {
+			return stats, err
+		}
+		r = decompressed
+	}
+	// Ввод перекодируется в UTF-8 до поиска, поэтому строки результата всегда в UTF-8
+	if s.opts.Encoding != "" {
+		decoded, err := DecodeReader(r, s.opts.Encoding)
+		if err != nil {
+			return stats, err
+		}
+		r = decoded
+	}
+
+	reader := bufio.NewReaderSize(r, 2*BinaryCheckSize)
+	if s.opts.BinaryFiles != BinaryFilesText {
+		block, err := reader.Peek(BinaryCheckSize)
+		if err != nil && err != io.EOF {
+			return stats, err
+		}
+		stats.Binary = IsBinary(block, s.opts.NullData)
+	}
+	if stats.Binary && s.opts.BinaryFiles == BinaryFilesWithoutMatch {
+		return stats, nil
+	}
+
+	var fnErr error
+	f := s.NewFeeder(func(m Match) {
+		if fnErr == nil {
+			fnErr = fn(m)
+		}
+	})
+	// Строки двоичного ввода не передаются: достаточно узнать, есть ли совпадение
+	binary := stats.Binary && !s.opts.CountOnly
+	f.silent = binary
+
+	terminator := s.opts.Terminator()
+	var err error
+	for {
+		var line string
+		line, err = reader.ReadString(terminator)
+		if line != "" {
+			f.Feed(s.opts.TrimRecord(line), len(line))
+		}
+		if fnErr != nil {
+			err = fnErr
+			break
+		}
+		// В двоичном вводе хватает одного совпадения
+		if f.Done() || (binary && f.matches > 0) {
+			err = nil
+			break
+		}
+		if err != nil {
+			break
+		}
+		if f.lineNum%ctxCheckInterval == 0 && ctx.Err() != nil {
+			err = ctx.Err()
+			break
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+
+	feederStats := f.Stats()
+	feederStats.Binary = stats.Binary
+	return feederStats, err
+}
+
+// All возвращает итератор по строкам результата; ошибка поиска передаётся
+// последним элементом. Досрочный выход из цикла прекращает чтение r.
+func (s *Searcher) All(ctx context.Context, r io.Reader) iter.Seq2[Match, error] {
+	return func(yield func(Match, error) bool) {
+		_, err := s.Search(ctx, r, func(m Match) error {
+			if !yield(m, nil) {
+				return errStopIteration
+			}
+			return nil
+		})
+		if err != nil && err != errStopIteration {
+			yield(Match{}, err)
+		}
+	}
+}
+
+// historyLine - строка, сохранённая для вывода контекста до совпадения
+type historyLine struct {
+	text   string
+	offset int64
+}
+
+// Feeder - потоковый поиск по строкам, которые подаёт вызывающий код, например
+// при чтении дописываемого файла. Строки результата передаются в fn по мере появления.
+type Feeder struct {
+	s      *Searcher
+	fn     func(Match)
+	silent bool // не передавать строки в fn, только считать
+
+	history   []historyLine // последние Before строк для вывода контекста до совпадения
+	lineNum   int
+	offset    int64 // смещение начала следующей строки
+	lastOut   int   // номер последней переданной строки, чтобы не передавать контекст дважды
+	afterLeft int
+	matches   int // число выбранных строк
+	spans     int // число вхождений в выбранных строках
+}
+
+// NewFeeder начинает поиск по строкам с первой строки и нулевого смещения
+func (s *Searcher) NewFeeder(fn func(Match)) *Feeder {
+	f := &Feeder{s: s, fn: fn}
+	if s.opts.Before > 0 {
+		f.history = make([]historyLine, s.opts.Before)
+	}
+	return f
+}
+
+// limitReached сообщает, что выбрано уже MaxCount строк
+func (f *Feeder) limitReached() bool {
+	return f.s.opts.MaxCount > 0 && f.matches >= f.s.opts.MaxCount
+}
+
+// Done сообщает, что дальнейшие строки не нужны: выбрано MaxCount строк и передан
+// весь контекст после последней из них, либо при Quiet найдено совпадение
+func (f *Feeder) Done() bool {
+	if f.s.opts.Quiet && f.matches > 0 {
+		return true
+	}
+	return f.limitReached() && f.afterLeft == 0
+}
+
+// Stats возвращает итоги поиска по поданным строкам
+func (f *Feeder) Stats() Stats {
+	return Stats{BytesSearched: f.offset, MatchedLines: f.matches, Matches: f.spans}
+}
+
+// Feed обрабатывает очередную строку без разделителя; size - её длина во вводе вместе с разделителем
+func (f *Feeder) Feed(line string, size int) {
+	f.lineNum++
+	offset := f.offset
+	f.offset += int64(size)
+
+	// После MaxCount совпадений передаётся только оставшийся контекст
+	if f.limitReached() {
+		if f.afterLeft > 0 {
+			f.output(f.lineNum, offset, line, false)
+			f.afterLeft--
+		}
+		return
+	}
+
+	opts := &f.s.opts
+	switch {
+	case f.s.m.Match(line) != opts.InvertMatch:
+		f.matches++
+		f.flushBefore()
+		f.output(f.lineNum, offset, line, true)
+		f.afterLeft = opts.After
+	case f.afterLeft > 0:
+		f.output(f.lineNum, offset, line, false)
+		f.afterLeft--
+	}
+	if opts.Before > 0 {
+		f.history[f.lineNum%opts.Before] = historyLine{text: line, offset: offset}
+	}
+}
+
+// flushBefore передаёт ещё не переданные строки контекста до текущей
+func (f *Feeder) flushBefore() {
+	before := f.s.opts.Before
+	first := f.lineNum - before
+	if first <= f.lastOut {
+		first = f.lastOut + 1
+	}
+	if first < 1 {
+		first = 1
+	}
+	for n := first; n < f.lineNum; n++ {
+		h := f.history[n%before]
+		f.output(n, h.offset, h.text, false)
+	}
+}
+
+func (f *Feeder) output(lineNum int, offset int64, line string, matched bool) {
+	f.lastOut = lineNum
+	opts := &f.s.opts
+	m := Match{LineNumber: lineNum, Offset: offset, Line: line, Context: !matched}
+	if matched && opts.Submatches && !opts.InvertMatch {
+		m.Submatches = f.s.m.FindAll(line)
+		if opts.Replace {
+			m.Replacements = Replacements(f.s.m, opts.Replacement, line, m.Submatches)
+		}
+	}
+	if matched {
+		f.spans += len(m.Submatches)
+	}
+	if !opts.Quiet && !opts.CountOnly && !f.silent {
+		f.fn(m)
+	}
+}
@@ -0,0 +1,129 @@
+package grep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const searcherInput = "start\nERROR one\nnext\nok\nERROR two ERROR\nend\n"
+
+func TestSearcherSearch(t *testing.T) {
+	m, err := NewMatcher([]string{"ERROR"}, Options{FixedStrings: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSearcher(m, Options{Before: 1, Submatches: true})
+
+	var got []Match
+	stats, err := s.Search(context.Background(), strings.NewReader(searcherInput), func(m Match) error {
+		got = append(got, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Match{
+		{LineNumber: 1, Offset: 0, Line: "start", Context: true},
+		{LineNumber: 2, Offset: 6, Line: "ERROR one", Submatches: []Span{{Start: 0, End: 5}}},
+		{LineNumber: 4, Offset: 21, Line: "ok", Context: true},
+		{LineNumber: 5, Offset: 24, Line: "ERROR two ERROR", Submatches: []Span{{Start: 0, End: 5}, {Start: 10, End: 15}}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %+v, want %+v", got, expected)
+	}
+	if want := (Stats{BytesSearched: int64(len(searcherInput)), MatchedLines: 2, Matches: 3}); stats != want {
+		t.Errorf("stats %+v, want %+v", stats, want)
+	}
+}
+
+func TestSearcherStopsOnCallbackError(t *testing.T) {
+	m := NewLiteralMatcher("ERROR")
+	s := NewSearcher(m, Options{})
+	stop := errors.New("хватит")
+
+	calls := 0
+	_, err := s.Search(context.Background(), strings.NewReader(searcherInput), func(Match) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("err = %v после %d вызовов, ожидалась ошибка обработчика после одного", err, calls)
+	}
+}
+
+func TestSearcherAll(t *testing.T) {
+	m, err := NewRegexMatcher([]string{`ERROR (\w+)`}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSearcher(m.WithCaptures(), Options{Replace: true, Replacement: "E:$1"})
+
+	var lines []string
+	for match, err := range s.All(context.Background(), strings.NewReader(searcherInput)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, fmt.Sprint(match.LineNumber, match.Replacements))
+		break
+	}
+	if want := []string{"2 [E:one]"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
+
+func TestSearcherBinaryInput(t *testing.T) {
+	input := "header\x00\nERROR core dumped\nERROR again\n"
+	m := NewLiteralMatcher("ERROR")
+
+	called := false
+	stats, err := NewSearcher(m, Options{}).Search(context.Background(), strings.NewReader(input), func(Match) error {
+		called = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if called || !stats.Binary || stats.MatchedLines != 1 {
+		t.Errorf("двоичный ввод: вызов обработчика %v, stats %+v", called, stats)
+	}
+
+	stats, err = NewSearcher(m, Options{CountOnly: true}).Search(context.Background(), strings.NewReader(input), func(Match) error {
+		t.Error("при CountOnly обработчик не вызывается")
+		return nil
+	})
+	if err != nil || stats.MatchedLines != 2 {
+		t.Errorf("CountOnly: stats %+v, err %v", stats, err)
+	}
+}
+
+func TestNewMatcherKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		opts     Options
+		line     string
+		expected []Span
+	}{
+		{"литерал", []string{"ab"}, Options{FixedStrings: true}, "abab", []Span{{Start: 0, End: 2}, {Start: 2, End: 4}}},
+		{"несколько строк без учёта регистра", []string{"ab", "CD"}, Options{FixedStrings: true, IgnoreCase: true}, "AB cd", []Span{{Start: 0, End: 2}, {Start: 3, End: 5, Pattern: 1}}},
+		{"регулярное выражение", []string{`a+`, `\d`}, Options{}, "aa 1", []Span{{Start: 0, End: 2}, {Start: 3, End: 4, Pattern: 1}}},
+		{"нечёткий поиск", []string{"abcd"}, Options{FixedStrings: true, Fuzzy: 1}, "xabdx", []Span{{Start: 1, End: 4, Distance: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMatcher(tt.patterns, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.FindAll(tt.line); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("got %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ignoreFileNames - файлы с правилами исключения в порядке возрастания приоритета:
+// правила более поздних файлов одного каталога перекрывают правила более ранних
+var ignoreFileNames = []string{".gitignore", ".ignore", ".grepignore"}
+
+// ignoreRule - одна строка файла исключений в синтаксисе .gitignore
+type ignoreRule struct {
+	base     string   // каталог файла исключений; пути проверяются относительно него
+	segments []string // паттерн, разбитый по '/'; для паттерна без '/' первый сегмент "**"
+	negate   bool     // правило с '!' возвращает ранее исключённый путь
+	dirOnly  bool     // паттерн с завершающим '/' относится только к каталогам
+	source   string   // файл и строка правила для --debug
+	text     string
+}
+
+// parseIgnoreLine разбирает строку файла исключений; ok = false для пустых строк и комментариев
+func parseIgnoreLine(line string) (rule ignoreRule, ok bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" || line[0] == '#' {
+		return rule, false
+	}
+	// Хвостовые пробелы отбрасываются, если не экранированы
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	rule.text = line
+
+	switch {
+	case line[0] == '!':
+		rule.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false
+	}
+
+	// Паттерн без '/' (кроме завершающего) совпадает с именем на любой глубине,
+	// паттерн с '/' привязан к каталогу файла исключений
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if !anchored {
+		rule.segments = []string{"**"}
+	}
+	for _, segment := range strings.Split(line, "/") {
+		// В .gitignore отрицание класса символов записывается как [!...], в path.Match - как [^...]
+		segment = strings.ReplaceAll(segment, "[!", "[^")
+		rule.segments = append(rule.segments, segment)
+	}
+	return rule, true
+}
+
+// matches сообщает, подходит ли путь rel (относительно base, через '/') под правило
+func (r *ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	return matchSegments(r.segments, strings.Split(rel, "/"))
+}
+
+// matchSegments сопоставляет сегменты паттерна с сегментами пути; "**" совпадает
+// с любым числом каталогов, а завершающий "**" - только с непустым остатком пути
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		if len(pattern) == 1 {
+			return len(name) > 0
+		}
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	// Некорректный паттерн ни с чем не совпадает
+	if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+// readIgnoreFiles читает правила исключения каталога dir; отсутствующие файлы пропускаются
+func readIgnoreFiles(dir string) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	for _, name := range ignoreFileNames {
+		fileName := filepath.Join(dir, name)
+		file, err := os.Open(fileName)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return rules, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			rule, ok := parseIgnoreLine(scanner.Text())
+			if !ok {
+				continue
+			}
+			rule.base = dir
+			rule.source = fileName + ":" + strconv.Itoa(lineNum)
+			rules = append(rules, rule)
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return rules, err
+		}
+	}
+	return rules, nil
+}
+
+// ignoredBy возвращает правило, исключающее путь, или nil. Правила идут от корня вглубь,
+// решает последнее подходящее, поэтому '!' и правила вложенных каталогов
+// перекрывают более ранние.
+func ignoredBy(rules []ignoreRule, name string, isDir bool) *ignoreRule {
+	var last *ignoreRule
+	for i := range rules {
+		rule := &rules[i]
+		rel, err := filepath.Rel(rule.base, name)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if rule.matches(filepath.ToSlash(rel), isDir) {
+			last = rule
+		}
+	}
+	if last == nil || last.negate {
+		return nil
+	}
+	return last
+}
+
+// isHidden сообщает, скрыт ли файл или каталог: имя начинается с точки
+func isHidden(name string) bool {
+	base := filepath.Base(name)
+	return len(base) > 1 && base[0] == '.' && base != ".."
+}
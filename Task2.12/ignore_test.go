@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreRuleMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"*.log", "app.log", false, true},
+		{"*.log", "logs/deep/app.log", false, true},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"doc/*.txt", "doc/a.txt", false, true},
+		{"doc/*.txt", "doc/sub/a.txt", false, false},
+		{"**/tmp", "a/b/tmp", true, true},
+		{"a/**/b", "a/b", false, true},
+		{"a/**/b", "a/x/y/b", false, true},
+		{"a/**", "a/x/y", false, true},
+		{"a/**", "a", true, false},
+		{"file[!0-9].txt", "filex.txt", false, true},
+		{"file[!0-9].txt", "file1.txt", false, false},
+		{`\#notes`, "#notes", false, true},
+		{"trailing   ", "trailing", false, true},
+	}
+
+	for _, tt := range tests {
+		rule, ok := parseIgnoreLine(tt.pattern)
+		if !ok {
+			t.Fatalf("паттерн %q не разобран", tt.pattern)
+		}
+		if got := rule.matches(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("%q для %q (каталог: %v) = %v, ожидалось %v", tt.pattern, tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{"", "# комментарий", "/"} {
+		if _, ok := parseIgnoreLine(line); ok {
+			t.Errorf("строка %q не должна давать правило", line)
+		}
+	}
+}
+
+// writeTree создаёт файлы с заданным содержимым, пути разделены '/'
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWalkSourcesHonorsIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".gitignore":          "vendor/\n/build\n*.log\n!keep.log\n",
+		".git/HEAD":           "x",
+		".env":                "x",
+		"vendor/lib/a.go":     "x",
+		"build/out":           "x",
+		"src/build/b.go":      "x",
+		"src/app.log":         "x",
+		"src/keep.log":        "x",
+		"src/main.go":         "x",
+		"src/.ignore":         "*.go\n",
+		"src/gen/.grepignore": "!*.go\n",
+		"src/gen/z.go":        "x",
+	})
+
+	walk := func(opts walkOptions) []string {
+		var got []string
+		for src := range walkSources(context.Background(), []string{dir}, opts) {
+			if src.err != nil {
+				t.Fatal(src.err)
+			}
+			rel, _ := filepath.Rel(dir, src.path)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		return got
+	}
+
+	var skipped []string
+	got := walk(walkOptions{recursive: true, debug: func(msg string) { skipped = append(skipped, msg) }})
+	// Правило вложенного каталога перекрывает правило родителя, '!' возвращает файл
+	want := []string{"src/gen/z.go", "src/keep.log"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("с учётом исключений %v, ожидалось %v", got, want)
+	}
+	if len(skipped) == 0 || !strings.Contains(strings.Join(skipped, "\n"), ".gitignore:1: vendor/") {
+		t.Errorf("--debug не сообщил о пропущенном vendor: %v", skipped)
+	}
+
+	got = walk(walkOptions{recursive: true, noIgnore: true, hidden: true})
+	if len(got) != 12 {
+		t.Errorf("с --no-ignore --hidden ожидалось 12 файлов, получили %v", got)
+	}
+}
+
+func TestRunDebugReportsSkippedPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".grepignore": "skip.txt\n",
+		"skip.txt":    "needle\n",
+		"keep.txt":    "needle\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-r", "--debug", "needle", dir}, &stdout, &stderr)
+	if code != exitMatch {
+		t.Fatalf("код завершения %d, stderr: %s", code, stderr.String())
+	}
+	if stdout.String() != filepath.Join(dir, "keep.txt")+":needle\n" {
+		t.Errorf("вывод %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "пропущен "+filepath.Join(dir, "skip.txt")) {
+		t.Errorf("stderr не содержит пропущенный файл: %q", stderr.String())
+	}
+}
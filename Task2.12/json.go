@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+// События --json в формате JSON Lines, совместимом с ripgrep:
+// begin и end обрамляют результаты файла с совпадениями, match и context
+// описывают строки, а summary завершает вывод общей статистикой.
+
+type jsonEvent struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+type jsonText struct {
+	Text string `json:"text"`
+}
+
+type jsonBegin struct {
+	Path jsonText `json:"path"`
+}
+
+type jsonSubmatch struct {
+	Match jsonText `json:"match"`
+	Start int      `json:"start"`
+	End   int      `json:"end"`
+	// Distance - число правок при --fuzzy
+	Distance *int `json:"distance,omitempty"`
+	// Replacement - текст замены при --replace
+	Replacement *jsonText `json:"replacement,omitempty"`
+}
+
+type jsonLine struct {
+	Path           jsonText       `json:"path"`
+	Lines          jsonText       `json:"lines"`
+	LineNumber     int            `json:"line_number"`
+	AbsoluteOffset int64          `json:"absolute_offset"`
+	Submatches     []jsonSubmatch `json:"submatches"`
+}
+
+type jsonDuration struct {
+	Secs  int64  `json:"secs"`
+	Nanos int    `json:"nanos"`
+	Human string `json:"human"`
+}
+
+type jsonStats struct {
+	Elapsed           jsonDuration `json:"elapsed"`
+	Searches          int          `json:"searches"`
+	SearchesWithMatch int          `json:"searches_with_match"`
+	BytesSearched     int64        `json:"bytes_searched"`
+	BytesPrinted      int64        `json:"bytes_printed"`
+	MatchedLines      int          `json:"matched_lines"`
+	Matches           int          `json:"matches"`
+}
+
+type jsonEnd struct {
+	Path  jsonText  `json:"path"`
+	Stats jsonStats `json:"stats"`
+}
+
+type jsonSummary struct {
+	ElapsedTotal jsonDuration `json:"elapsed_total"`
+	Stats        jsonStats    `json:"stats"`
+}
+
+func newJSONDuration(d time.Duration) jsonDuration {
+	return jsonDuration{
+		Secs:  int64(d / time.Second),
+		Nanos: int(d % time.Second),
+		Human: fmt.Sprintf("%.6fs", d.Seconds()),
+	}
+}
+
+func newJSONStats(stats searchStats) jsonStats {
+	return jsonStats{
+		Elapsed:           newJSONDuration(stats.elapsed),
+		Searches:          stats.searches,
+		SearchesWithMatch: stats.searchesWithMatch,
+		BytesSearched:     stats.bytesSearched,
+		BytesPrinted:      stats.bytesPrinted,
+		MatchedLines:      stats.matchedLines,
+		Matches:           stats.matches,
+	}
+}
+
+// writeJSONEvent пишет событие одной строкой
+func writeJSONEvent(w io.Writer, eventType string, data any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(jsonEvent{Type: eventType, Data: data})
+}
+
+// writeJSONSummary пишет итоговое событие summary
+func writeJSONSummary(w io.Writer, stats searchStats, elapsed time.Duration) error {
+	return writeJSONEvent(w, "summary", jsonSummary{
+		ElapsedTotal: newJSONDuration(elapsed),
+		Stats:        newJSONStats(stats),
+	})
+}
+
+// jsonPrinter - вывод результатов файла событиями --json
+type jsonPrinter struct {
+	path    jsonText
+	params  *SearchParams
+	out     *bytes.Buffer
+	begun   bool // событие begin уже выведено
+	printed int64
+}
+
+func newJSONPrinter(path string, params *SearchParams, out *bytes.Buffer) *jsonPrinter {
+	return &jsonPrinter{path: jsonText{Text: path}, params: params, out: out}
+}
+
+func (p *jsonPrinter) write(eventType string, data any) {
+	before := p.out.Len()
+	// Запись в bytes.Buffer не возвращает ошибок, а все значения сериализуемы
+	_ = writeJSONEvent(p.out, eventType, data)
+	p.printed += int64(p.out.Len() - before)
+}
+
+func (p *jsonPrinter) printLine(m grep.Match) {
+	if !p.begun {
+		p.write("begin", jsonBegin{Path: p.path})
+		p.begun = true
+	}
+
+	eventType := "context"
+	submatches := []jsonSubmatch{}
+	if !m.Context {
+		eventType = "match"
+		if !p.params.invertMatch {
+			for i, sp := range m.Submatches {
+				submatch := jsonSubmatch{
+					Match: jsonText{Text: m.Line[sp.Start:sp.End]},
+					Start: sp.Start,
+					End:   sp.End,
+				}
+				if p.params.fuzzy > 0 {
+					distance := sp.Distance
+					submatch.Distance = &distance
+				}
+				if p.params.replace {
+					submatch.Replacement = &jsonText{Text: m.Replacements[i]}
+				}
+				submatches = append(submatches, submatch)
+			}
+		}
+	}
+
+	terminator := "\n"
+	if p.params.nullData {
+		terminator = "\x00"
+	}
+	p.write(eventType, jsonLine{
+		Path:           p.path,
+		Lines:          jsonText{Text: m.Line + terminator},
+		LineNumber:     m.LineNumber,
+		AbsoluteOffset: m.Offset,
+		Submatches:     submatches,
+	})
+}
+
+// finish завершает файл событием end, если по нему что-то было выведено
+func (p *jsonPrinter) finish(stats *searchStats) {
+	if !p.begun {
+		return
+	}
+	stats.bytesPrinted = p.printed
+	p.write("end", jsonEnd{Path: p.path, Stats: newJSONStats(*stats)})
+}
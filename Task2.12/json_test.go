@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// normalizeJSONEvents разбирает вывод --json и убирает зависящие от времени поля
+func normalizeJSONEvents(t *testing.T, output string) []string {
+	t.Helper()
+	var events []string
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("некорректная строка JSON %q: %v", line, err)
+		}
+		data := event["data"].(map[string]any)
+		delete(data, "elapsed_total")
+		if stats, ok := data["stats"].(map[string]any); ok {
+			delete(stats, "elapsed")
+		}
+		normalized, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, string(normalized))
+	}
+	return events
+}
+
+func TestRunJSONOutput(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	quietFile := filepath.Join(dir, "quiet.log")
+	if err := os.WriteFile(logFile, []byte("start\nERROR one ERROR\nnext\nok\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(quietFile, []byte("nothing here\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--json", "-A", "1", "ERROR", logFile, quietFile}, &stdout, &stderr)
+	if code != exitMatch {
+		t.Fatalf("код завершения %d, stderr: %s", code, stderr.String())
+	}
+
+	path, _ := json.Marshal(logFile)
+	expected := []string{
+		`{"data":{"path":{"text":` + string(path) + `}},"type":"begin"}`,
+		`{"data":{"absolute_offset":6,"line_number":2,"lines":{"text":"ERROR one ERROR\n"},"path":{"text":` + string(path) + `},` +
+			`"submatches":[{"end":5,"match":{"text":"ERROR"},"start":0},{"end":15,"match":{"text":"ERROR"},"start":10}]},"type":"match"}`,
+		`{"data":{"absolute_offset":22,"line_number":3,"lines":{"text":"next\n"},"path":{"text":` + string(path) + `},"submatches":[]},"type":"context"}`,
+		`{"data":{"path":{"text":` + string(path) + `},"stats":{"bytes_printed":` + endBytesPrinted(t, stdout.String()) + `,"bytes_searched":30,` +
+			`"matched_lines":1,"matches":2,"searches":1,"searches_with_match":1}},"type":"end"}`,
+		`{"data":{"stats":{"bytes_printed":` + endBytesPrinted(t, stdout.String()) + `,"bytes_searched":43,` +
+			`"matched_lines":1,"matches":2,"searches":2,"searches_with_match":1}},"type":"summary"}`,
+	}
+
+	got := normalizeJSONEvents(t, stdout.String())
+	if len(got) != len(expected) {
+		t.Fatalf("получено %d событий, ожидалось %d:\n%s", len(got), len(expected), stdout.String())
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("событие %d:\n got %s\nwant %s", i, got[i], expected[i])
+		}
+	}
+}
+
+// endBytesPrinted возвращает объём вывода до события end: begin, match и context
+func endBytesPrinted(t *testing.T, output string) string {
+	t.Helper()
+	lines := strings.SplitAfter(output, "\n")
+	size := 0
+	for _, line := range lines[:3] {
+		size += len(line)
+	}
+	out, err := json.Marshal(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestRunJSONRejectsCount(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--json", "-c", "x", "-"}, &stdout, &stderr); code != exitTrouble {
+		t.Errorf("код завершения %d, ожидался %d", code, exitTrouble)
+	}
+}
@@ -2,11 +2,23 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"strconv"
+	"os/signal"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
 )
 
 /*
@@ -26,192 +38,462 @@ import (
 	-n - "line num": напечатать номер строки.
 */
 
-const outputFileName = "grep_result.txt"
-
 // SearchParams - структура для хранения параметров поиска
 type SearchParams struct {
-	afterLines   int
-	beforeLines  int
-	contextLines int
-	countOnly    bool
-	ignoreCase   bool
-	invertMatch  bool
-	fixedString  bool
-	lineNumber   bool
+	afterLines    int
+	beforeLines   int
+	contextLines  int
+	countOnly     bool
+	ignoreCase    bool
+	ignoreYo      bool // считать ё и е одной буквой (--ignore-yo)
+	invertMatch   bool
+	fixedString   bool
+	lineNumber    bool
+	onlyMatching  bool
+	color         bool
+	quiet         bool
+	maxCount      int    // максимум выбранных строк в файле; 0 или меньше - без ограничения
+	binaryFiles   string // режим обработки двоичных файлов
+	nullData      bool   // записи разделяются нулевым байтом (-z)
+	json          bool   // вывод событиями JSON Lines
+	countMatches  bool   // -c считает вхождения, а не строки
+	stats         bool   // вывести статистику поиска
+	label         string // имя стандартного ввода в выводе
+	byteOffset    bool   // печатать смещение в байтах (-b)
+	nullAfterName bool   // завершать имя файла нулевым байтом (-Z)
+	initialTab    bool   // выравнивать содержимое строк по табуляции (-T)
+	searchZip     bool   // распаковывать сжатые файлы
+	encoding      string // кодировка ввода; пустая строка - без перекодирования
+	fuzzy         int    // допустимое число правок для -F; 0 - точный поиск
+	replace       bool   // выводить строки с заменой вхождений (--replace)
+	replacement   string // шаблон замены; может быть пустым
 }
 
-func main() {
-	// Флаги для запуска утилиты
-	afterLines := flag.Int("A", 0, "печатать +N строк после совпадения")
-	beforeLines := flag.Int("B", 0, "печатать +N строк до совпадения")
-	contextLines := flag.Int("C", 0, "печатать ±N строк вокруг совпадения")
-	countOnly := flag.Bool("c", false, "количество строк")
-	ignoreCase := flag.Bool("i", false, "игнорировать регистр")
-	invertMatch := flag.Bool("v", false, "вместо совпадения, исключать")
-	fixedString := flag.Bool("F", false, "точное совпадение со строкой, не паттерн")
-	lineNumber := flag.Bool("n", false, "печатать номер строки")
-
-	flag.Parse()
-
-	args := flag.Args()
-
-	if len(args) < 2 {
-		fmt.Println("Необходимо указать паттерн и имя файла.")
-		os.Exit(1)
+// options переводит параметры утилиты в параметры поиска пакета grep
+func (p *SearchParams) options() grep.Options {
+	before, after := p.beforeLines, p.afterLines
+	// Флаг -C переопределяет -A и -B
+	if p.contextLines > 0 {
+		before, after = p.contextLines, p.contextLines
 	}
-
-	pattern := args[0]
-	fileName := args[1]
-
-	// Создаем структуру с параметрами поиска
-	params := &SearchParams{
-		afterLines:   *afterLines,
-		beforeLines:  *beforeLines,
-		contextLines: *contextLines,
-		countOnly:    *countOnly,
-		ignoreCase:   *ignoreCase,
-		invertMatch:  *invertMatch,
-		fixedString:  *fixedString,
-		lineNumber:   *lineNumber,
+	return grep.Options{
+		IgnoreCase:   p.ignoreCase,
+		IgnoreYo:     p.ignoreYo,
+		FixedStrings: p.fixedString,
+		Fuzzy:        p.fuzzy,
+		InvertMatch:  p.invertMatch,
+		Before:       before,
+		After:        after,
+		MaxCount:     p.maxCount,
+		CountOnly:    p.countOnly,
+		Quiet:        p.quiet,
+		BinaryFiles:  p.binaryFiles,
+		NullData:     p.nullData,
+		Decompress:   p.searchZip,
+		Encoding:     p.encoding,
+		Submatches:   needSpans(p),
+		Replace:      p.replace,
+		Replacement:  p.replacement,
 	}
+}
 
+// newMatcher создаёт поиск паттернов по параметрам утилиты
+func newMatcher(patterns []string, params *SearchParams) (grep.Matcher, error) {
+	return grep.NewMatcher(patterns, params.options())
+}
+
+// readPatternFile читает паттерны из файла, по одному на строку
+func readPatternFile(fileName string) ([]string, error) {
 	file, err := os.Open(fileName)
 	if err != nil {
-		fmt.Println("Ошибка открытия файла:", err)
-		os.Exit(1)
+		return nil, err
 	}
 	defer file.Close()
 
-	// Считываем строки из файла
-	var lines []string
+	var patterns []string
 	scanner := bufio.NewScanner(file)
-
 	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+		patterns = append(patterns, strings.TrimSuffix(scanner.Text(), "\r"))
 	}
 	if err := scanner.Err(); err != nil {
-		fmt.Println("Ошибка чтения файла:", err)
-		os.Exit(1)
+		return nil, err
 	}
+	return patterns, nil
+}
+
+// patternList - значение повторяемого флага (-e, -f)
+type patternList []string
+
+func (p *patternList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patternList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
 
-	// Выполняем поиск и получаем результат
-	result := findMatchingLines(lines, pattern, params)
+// Коды завершения в соответствии с POSIX
+const (
+	exitMatch   = 0 // найдена хотя бы одна строка
+	exitNoMatch = 1 // ничего не найдено
+	exitTrouble = 2 // ошибка
+)
+
+// outputFileName - файл, в который записываются результаты поиска
+const outputFileName = "grep_result.txt"
 
-	// Открытие файла для записи
+func main() {
 	outputFile, err := os.Create(outputFileName)
 	if err != nil {
-		fmt.Println("Ошибка создания файла:", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Ошибка создания файла:", err)
+		os.Exit(exitTrouble)
+	}
+	code := run(os.Args[1:], outputFile, os.Stderr)
+	if err := outputFile.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка записи в файл:", err)
+		code = exitTrouble
 	}
-	defer outputFile.Close()
+	os.Exit(code)
+}
 
-	// Пишем результаты в файл
-	if params.countOnly {
-		_, err = outputFile.WriteString(strconv.Itoa(len(result)))
-	} else {
-		for idx, line := range result {
-			_, err = outputFile.WriteString(line)
-			if idx != len(result)-1 {
-				_, err = outputFile.WriteString("\n")
-			}
+// run разбирает аргументы, выполняет поиск и возвращает код завершения
+func run(arguments []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("grep", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	// Флаги для запуска утилиты
+	afterLines := flags.Int("A", 0, "печатать +N строк после совпадения")
+	beforeLines := flags.Int("B", 0, "печатать +N строк до совпадения")
+	contextLines := flags.Int("C", 0, "печатать ±N строк вокруг совпадения")
+	countOnly := flags.Bool("c", false, "количество строк")
+	ignoreCase := flags.Bool("i", false, "игнорировать регистр")
+	ignoreYo := flags.Bool("ignore-yo", false, "не различать ё и е")
+	invertMatch := flags.Bool("v", false, "вместо совпадения, исключать")
+	fixedString := flags.Bool("F", false, "точное совпадение со строкой, не паттерн")
+	lineNumber := flags.Bool("n", false, "печатать номер строки")
+	onlyMatching := flags.Bool("o", false, "печатать только совпавшие части строк")
+	colorMode := flags.String("color", "auto", "подсветка совпадений: auto, always или never")
+	recursive := flags.Bool("r", false, "рекурсивно искать в каталогах")
+	noIgnore := flags.Bool("no-ignore", false, "при -r не учитывать .gitignore, .ignore и .grepignore")
+	hidden := flags.Bool("hidden", false, "при -r искать в скрытых файлах и каталогах")
+	debug := flags.Bool("debug", false, "сообщать о пропущенных при -r путях")
+	jobs := flags.Int("j", runtime.GOMAXPROCS(0), "число файлов, обрабатываемых параллельно")
+	quiet := flags.Bool("q", false, "ничего не выводить, завершиться при первом совпадении")
+	noMessages := flags.Bool("s", false, "не сообщать об ошибках чтения файлов")
+	maxCount := flags.Int("m", -1, "остановиться после NUM совпавших строк в каждом файле")
+	binaryFiles := flags.String("binary-files", grep.BinaryFilesBinary, "обработка двоичных файлов: binary, text или without-match")
+	binaryAsText := flags.Bool("a", false, "обрабатывать двоичные файлы как текст (--binary-files=text)")
+	skipBinary := flags.Bool("I", false, "пропускать двоичные файлы (--binary-files=without-match)")
+	nullData := flags.Bool("z", false, "строки ввода и вывода разделяются нулевым байтом")
+	jsonOutput := flags.Bool("json", false, "выводить результат событиями JSON Lines")
+	countMatches := flags.Bool("count-matches", false, "подсчитывать отдельные вхождения, а не строки")
+	printStats := flags.Bool("stats", false, "вывести статистику поиска после результатов")
+	label := flags.String("label", "", "имя стандартного ввода в выводе")
+	searchZip := flags.Bool("search-zip", false, "распаковывать файлы gzip и bzip2 при поиске")
+	follow := flags.Bool("follow", false, "следить за дописыванием файла, как tail -f")
+	encodingName := flags.String("encoding", grep.EncodingAuto, "кодировка ввода: auto, utf-8, cp1251, koi8-r, utf-16le или utf-16be")
+	fuzzy := flags.Int("fuzzy", 0, "приближённый поиск с -F: допускать до K правок (вставка, удаление, замена)")
+	replacement := flags.String("replace", "", "выводить строки с заменой вхождений; $1, ${name} - группы регулярного выражения")
+	inPlace := flags.Bool("in-place", false, "записать замены --replace в файлы")
+	backupSuffix := flags.String("backup", ".bak", "суффикс резервной копии для --in-place; пустой - без копии")
+	var byteOffset, nullAfterName, initialTab bool
+	flags.BoolVar(&byteOffset, "b", false, "печатать смещение строки (или вхождения с -o) в байтах")
+	flags.BoolVar(&byteOffset, "byte-offset", false, "то же, что -b")
+	flags.BoolVar(&nullAfterName, "Z", false, "завершать имя файла нулевым байтом вместо ':'")
+	flags.BoolVar(&nullAfterName, "null", false, "то же, что -Z")
+	flags.BoolVar(&initialTab, "T", false, "выравнивать начало строки по табуляции")
+	flags.BoolVar(&initialTab, "initial-tab", false, "то же, что -T")
+
+	var patterns, patternFiles patternList
+	flags.Var(&patterns, "e", "паттерн для поиска (можно указать несколько раз)")
+	flags.Var(&patternFiles, "f", "файл с паттернами, по одному на строку (можно указать несколько раз)")
+
+	if err := flags.Parse(arguments); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitMatch
 		}
+		return exitTrouble
 	}
-	if err != nil {
-		fmt.Println("Ошибка записи в файл:", err)
-		os.Exit(1)
+
+	args := flags.Args()
+
+	// Пустая замена допустима (удаляет вхождения), поэтому проверяем, задан ли флаг
+	replace := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "replace" {
+			replace = true
+		}
+	})
+
+	for _, patternFile := range patternFiles {
+		filePatterns, err := readPatternFile(patternFile)
+		if err != nil {
+			fmt.Fprintln(stderr, "Ошибка чтения файла паттернов:", err)
+			return exitTrouble
+		}
+		patterns = append(patterns, filePatterns...)
 	}
-}
 
-// contains проверяет, содержится ли элемент в срезе целых чисел.
-func contains(slice []int, element int) bool {
-	for _, value := range slice {
-		if value == element {
-			return true
+	// Без -e и -f паттерн передаётся первым позиционным аргументом
+	if len(patterns) == 0 && len(patternFiles) == 0 {
+		if len(args) == 0 {
+			fmt.Fprintln(stderr, "Необходимо указать паттерн и имя файла.")
+			return exitTrouble
 		}
+		patterns = append(patterns, args[0])
+		args = args[1:]
 	}
-	return false
-}
 
-// processLine определяет, соответствует ли строка паттерну с учётом флагов
-func processLine(line string, pattern string, params *SearchParams) bool {
-	if params.fixedString {
-		if params.ignoreCase {
-			return strings.Contains(strings.ToLower(line), strings.ToLower(pattern)) != params.invertMatch
+	// Без файлов читаем stdin, а с -r - текущий каталог
+	if len(args) == 0 {
+		if *recursive {
+			args = []string{"."}
+		} else {
+			args = []string{"-"}
 		}
-		return strings.Contains(line, pattern) != params.invertMatch
+	}
+	withName := len(args) > 1 || *recursive
+
+	if *follow && (len(args) != 1 || args[0] == "-" || *recursive) {
+		fmt.Fprintln(stderr, errFollowTarget)
+		return exitTrouble
+	}
+
+	color, err := resolveColor(*colorMode, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitTrouble
+	}
+
+	// --count-matches - разновидность -c
+	if *countMatches {
+		*countOnly = true
+	}
+	if *jsonOutput && *countOnly {
+		fmt.Fprintln(stderr, "флаг --json нельзя сочетать с -c")
+		return exitTrouble
 	}
 
-	// Обработка паттерна с '.' (замена на произвольный символ)
-	var n []int
-	for idx, value := range pattern {
-		if value == '.' {
-			n = append(n, idx)
+	if *fuzzy < 0 {
+		fmt.Fprintln(stderr, "число правок --fuzzy не может быть отрицательным")
+		return exitTrouble
+	}
+	if *fuzzy > 0 && !*fixedString {
+		fmt.Fprintln(stderr, "флаг --fuzzy работает только с -F")
+		return exitTrouble
+	}
+
+	if *inPlace {
+		switch {
+		case !replace:
+			fmt.Fprintln(stderr, "флаг --in-place требует --replace")
+			return exitTrouble
+		case *follow || slices.Contains(args, "-"):
+			fmt.Fprintln(stderr, errInPlaceTarget)
+			return exitTrouble
+		case *invertMatch || *searchZip || (*encodingName != grep.EncodingAuto && *encodingName != grep.EncodingUTF8):
+			fmt.Fprintln(stderr, "флаг --in-place нельзя сочетать с -v, --search-zip и перекодированием")
+			return exitTrouble
 		}
 	}
 
-	newPattern := strings.ReplaceAll(pattern, ".", "")
-	newLines := strings.Split(line, " ")
+	encoding, err := grep.ParseEncoding(*encodingName)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitTrouble
+	}
 
-	for _, newLine := range newLines {
-		var builder strings.Builder
-		for i, char := range newLine {
-			// contains проверяет, содержится ли элемент в срезе целых чисел.
-			if !contains(n, i) {
-				builder.WriteRune(char)
+	binaryMode, err := parseBinaryFiles(*binaryFiles, *binaryAsText, *skipBinary)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitTrouble
+	}
+
+	// Создаем структуру с параметрами поиска
+	params := &SearchParams{
+		afterLines:    *afterLines,
+		beforeLines:   *beforeLines,
+		contextLines:  *contextLines,
+		countOnly:     *countOnly,
+		ignoreCase:    *ignoreCase,
+		ignoreYo:      *ignoreYo,
+		invertMatch:   *invertMatch,
+		fixedString:   *fixedString,
+		lineNumber:    *lineNumber,
+		onlyMatching:  *onlyMatching,
+		color:         color,
+		quiet:         *quiet,
+		maxCount:      *maxCount,
+		binaryFiles:   binaryMode,
+		nullData:      *nullData,
+		json:          *jsonOutput,
+		countMatches:  *countMatches,
+		stats:         *printStats && !*jsonOutput,
+		label:         *label,
+		byteOffset:    byteOffset,
+		nullAfterName: nullAfterName,
+		initialTab:    initialTab,
+		searchZip:     *searchZip,
+		encoding:      encoding,
+		fuzzy:         *fuzzy,
+		replace:       replace,
+		replacement:   *replacement,
+	}
+
+	m, err := newMatcher(patterns, params)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitTrouble
+	}
+	searcher := grep.NewSearcher(m, params.options())
+
+	// -m 0 не выбирает ни одной строки, файлы можно не читать
+	if *maxCount == 0 {
+		return exitNoMatch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Пишем результаты в стандартный вывод в порядке файлов
+	writer := bufio.NewWriter(stdout)
+	started := time.Now()
+	var total searchStats
+	var matched atomic.Bool
+	hadErrors := false
+	search := func(ctx context.Context, src source) fileResult {
+		res := searchFile(ctx, src, searcher, params, withName)
+		if res.matched {
+			matched.Store(true)
+			if *inPlace && res.err == nil {
+				_, res.err = rewriteFile(src.path, m, params, *backupSuffix)
+			}
+			// С -q достаточно первого совпадения: останавливаем остальные потоки,
+			// если не нужно переписать все файлы
+			if params.quiet && !*inPlace {
+				cancel()
 			}
 		}
-
-		if params.ignoreCase {
-			if strings.Contains(strings.ToLower(builder.String()), strings.ToLower(newPattern)) != params.invertMatch {
-				return true
+		return res
+	}
+	emit := func(res fileResult) error {
+		total.add(res.stats)
+		if _, err := writer.Write(res.output); err != nil {
+			return err
+		}
+		if res.err != nil && !errors.Is(res.err, context.Canceled) {
+			hadErrors = true
+			if *noMessages {
+				return nil
 			}
-		} else {
-			if strings.Contains(builder.String(), newPattern) != params.invertMatch {
-				return true
+			// Перед сообщением об ошибке сбрасываем уже найденное, чтобы сохранить порядок
+			if err := writer.Flush(); err != nil {
+				return err
 			}
+			fmt.Fprintf(stderr, "grep: %s: %v\n", res.name, unwrapPathError(res.err))
 		}
+		return nil
 	}
 
-	return false
-}
+	if *follow {
+		// В режиме --follow поиск продолжается до SIGINT или SIGTERM
+		followCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		stats, followErr := followFile(followCtx, args[0], searcher, params, withName, writer)
+		err = emit(fileResult{name: args[0], matched: stats.searchesWithMatch > 0, stats: stats, err: followErr})
+		matched.Store(stats.searchesWithMatch > 0)
+	} else {
+		opts := walkOptions{recursive: *recursive, noIgnore: *noIgnore, hidden: *hidden, output: outputInfo(stdout)}
+		if *debug {
+			// Обход каталогов идёт в отдельной горутине, а ошибки пишет emit
+			stderr = &lockedWriter{w: stderr}
+			opts.debug = func(msg string) {
+				fmt.Fprintln(stderr, "grep: "+msg)
+			}
+		}
+		err = runSearch(ctx, walkSources(ctx, args, opts), *jobs, search, emit)
+	}
+	if errors.Is(err, context.Canceled) && params.quiet && matched.Load() {
+		err = nil
+	}
+	if err == nil && !params.quiet {
+		switch {
+		case params.json:
+			err = writeJSONSummary(writer, total, time.Since(started))
+		case params.stats:
+			err = writeStats(writer, total, time.Since(started))
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Ошибка записи:", err)
+		return exitTrouble
+	}
 
-// findMatchingLines ищет строки, соответствующие паттерну, и возвращает их с контекстом
-func findMatchingLines(lines []string, pattern string, params *SearchParams) []string {
-	var result []string
-	var matchingLineNumbers []int
+	switch {
+	case params.quiet && matched.Load():
+		return exitMatch
+	case hadErrors:
+		return exitTrouble
+	case matched.Load():
+		return exitMatch
+	default:
+		return exitNoMatch
+	}
+}
 
-	for num, line := range lines {
-		if processLine(line, pattern, params) {
-			matchingLineNumbers = append(matchingLineNumbers, num+1)
-		}
+// unwrapPathError убирает из ошибки операцию и путь: имя файла уже есть в сообщении
+func unwrapPathError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
 	}
+	return err
+}
 
-	for _, lineNumber := range matchingLineNumbers {
-		// Определяем начало и конец контекста
-		contextStart := lineNumber - params.beforeLines - 1
-		contextEnd := lineNumber + params.afterLines - 1
+// lockedWriter упорядочивает запись из нескольких горутин
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
 
-		// Корректируем контекст если есть флаг -C
-		if params.contextLines > 0 {
-			contextStart = lineNumber - params.contextLines - 1
-			contextEnd = lineNumber + params.contextLines - 1
-		}
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
 
-		// Обрезаем контекст чтобы не вылезти за границы массива строк
-		if contextStart < 0 {
-			contextStart = 0
-		}
-		if contextEnd >= len(lines) {
-			contextEnd = len(lines) - 1
-		}
+// outputInfo возвращает сведения о файле вывода, если результаты пишутся в обычный файл
+func outputInfo(out io.Writer) os.FileInfo {
+	file, ok := out.(*os.File)
+	if !ok {
+		return nil
+	}
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	return info
+}
 
-		for i := contextStart; i <= contextEnd; i++ {
-			outputLine := lines[i]
-			if params.lineNumber {
-				outputLine = strconv.Itoa(i+1) + ":" + outputLine
-			}
-			result = append(result, outputLine)
+// resolveColor определяет, нужна ли подсветка, по значению флага --color
+func resolveColor(mode string, out io.Writer) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		file, ok := out.(*os.File)
+		if !ok {
+			return false, nil
 		}
+		info, err := file.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	default:
+		return false, fmt.Errorf("некорректное значение --color: %q", mode)
 	}
-	return result
 }
@@ -1,11 +1,34 @@
 package main
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
 )
 
+// findMatchingLines прогоняет строки через grep.Searcher и возвращает вывод
+// построчно; с -c - одну строку с числом совпавших строк, как её печатает grep
+func findMatchingLines(lines []string, m grep.Matcher, params *SearchParams) []string {
+	var result []string
+	printer := newTextPrinter(params, "", func(line string) {
+		result = append(result, line)
+	})
+	f := grep.NewSearcher(m, params.options()).NewFeeder(printer.printLine)
+	for _, line := range lines {
+		f.Feed(line, len(line)+1)
+	}
+	if params.countOnly {
+		return []string{strconv.Itoa(f.Stats().MatchedLines)}
+	}
+	return result
+}
+
 func TestFindMatchingLinesFlags(t *testing.T) {
 	lines := []string{
 		"Hello world",
@@ -129,16 +152,364 @@ func TestFindMatchingLinesFlags(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := findMatchingLines(lines, tt.pattern, tt.params)
-
-			if tt.params.countOnly {
-				count := len(result)
-				result = []string{strconv.Itoa(count)}
+			m, err := newMatcher([]string{tt.pattern}, tt.params)
+			if err != nil {
+				t.Fatalf("newMatcher: %v", err)
 			}
+			result := findMatchingLines(lines, m, tt.params)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindMatchingLinesMultiplePatterns(t *testing.T) {
+	lines := []string{
+		"req-101 started",
+		"req-202 started",
+		"req-101 finished, req-303 queued",
+		"idle",
+	}
 
+	tests := []struct {
+		name     string
+		patterns []string
+		params   *SearchParams
+		expected []string
+	}{
+		{
+			name:     "-F с несколькими паттернами",
+			patterns: []string{"req-303", "req-202"},
+			params:   &SearchParams{fixedString: true},
+			expected: []string{"req-202 started", "req-101 finished, req-303 queued"},
+		},
+		{
+			name:     "-o -F сообщает каждое вхождение",
+			patterns: []string{"req-101", "req-303"},
+			params:   &SearchParams{fixedString: true, onlyMatching: true, lineNumber: true},
+			expected: []string{"1:req-101", "3:req-101", "3:req-303"},
+		},
+		{
+			name:     "регулярные выражения объединяются в альтернативу",
+			patterns: []string{"^idle$", "req-[0-9]+ finished"},
+			params:   &SearchParams{},
+			expected: []string{"req-101 finished, req-303 queued", "idle"},
+		},
+		{
+			name:     "-o с регулярными выражениями",
+			patterns: []string{"req-3\\d+", "(start|finish)ed"},
+			params:   &SearchParams{onlyMatching: true},
+			expected: []string{"started", "started", "finished", "req-303"},
+		},
+		{
+			name:     "-i -F с несколькими паттернами",
+			patterns: []string{"IDLE", "REQ-202"},
+			params:   &SearchParams{fixedString: true, ignoreCase: true},
+			expected: []string{"req-202 started", "idle"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newMatcher(tt.patterns, tt.params)
+			if err != nil {
+				t.Fatalf("newMatcher: %v", err)
+			}
+			result := findMatchingLines(lines, m, tt.params)
 			if !reflect.DeepEqual(result, tt.expected) {
 				t.Errorf("got %v, want %v", result, tt.expected)
 			}
 		})
 	}
 }
+
+func TestColorizeReportsPattern(t *testing.T) {
+	params := &SearchParams{color: true}
+	m, err := newMatcher([]string{"foo", "ba(r)"}, params)
+	if err != nil {
+		t.Fatalf("newMatcher: %v", err)
+	}
+
+	result := findMatchingLines([]string{"foo bar"}, m, params)
+	expected := []string{highlight("foo", 0) + " " + highlight("bar", 1)}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %q, want %q", result, expected)
+	}
+}
+
+func TestRunExitStatus(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	content := "start\nERROR one\nnext\nERROR two\nafter two\nERROR three\n"
+	if err := os.WriteFile(logFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.log")
+	emptyPatterns := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(emptyPatterns, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		code       int
+		stdout     string
+		wantStderr bool
+	}{
+		{
+			name:   "есть совпадение",
+			args:   []string{"ERROR one", logFile},
+			code:   exitMatch,
+			stdout: "ERROR one\n",
+		},
+		{
+			name: "нет совпадений",
+			args: []string{"FATAL", logFile},
+			code: exitNoMatch,
+		},
+		{
+			name:       "ошибка чтения файла важнее совпадения",
+			args:       []string{"ERROR one", logFile, missing},
+			code:       exitTrouble,
+			stdout:     logFile + ":ERROR one\n",
+			wantStderr: true,
+		},
+		{
+			name:       "-q с совпадением и ошибкой",
+			args:       []string{"-q", "ERROR", missing, logFile},
+			code:       exitMatch,
+			wantStderr: true,
+		},
+		{
+			name: "-q без совпадений",
+			args: []string{"-q", "FATAL", logFile},
+			code: exitNoMatch,
+		},
+		{
+			name: "-s скрывает сообщение, но не код",
+			args: []string{"-s", "ERROR", missing},
+			code: exitTrouble,
+		},
+		{
+			name:   "-m останавливается после NUM строк",
+			args:   []string{"-m", "2", "ERROR", logFile},
+			code:   exitMatch,
+			stdout: "ERROR one\nERROR two\n",
+		},
+		{
+			name:   "-m с завершающим контекстом",
+			args:   []string{"-m", "1", "-A", "2", "-n", "ERROR", logFile},
+			code:   exitMatch,
+			stdout: "2:ERROR one\n3:next\n4:ERROR two\n",
+		},
+		{
+			name: "-m 0",
+			args: []string{"-m", "0", "ERROR", logFile},
+			code: exitNoMatch,
+		},
+		{
+			name: "-f с пустым файлом ничего не находит",
+			args: []string{"-f", emptyPatterns, logFile},
+			code: exitNoMatch,
+		},
+		{
+			name:   "-v -f с пустым файлом выбирает все строки",
+			args:   []string{"-v", "-f", emptyPatterns, logFile},
+			code:   exitMatch,
+			stdout: content,
+		},
+		{
+			name:       "некорректный паттерн",
+			args:       []string{"ERROR(", logFile},
+			code:       exitTrouble,
+			wantStderr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("код завершения %d, ожидался %d (stderr: %q)", code, tt.code, stderr.String())
+			}
+			if stdout.String() != tt.stdout {
+				t.Errorf("stdout %q, ожидался %q", stdout.String(), tt.stdout)
+			}
+			if (stderr.Len() > 0) != tt.wantStderr {
+				t.Errorf("неожиданный stderr: %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunCountModes(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	if err := os.WriteFile(first, []byte("a\nERROR x ERROR\nb\nERROR y\nc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("nothing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdout string
+	}{
+		{
+			name:   "-c считает строки, а не контекст",
+			args:   []string{"-c", "-C", "2", "ERROR", first},
+			stdout: "2\n",
+		},
+		{
+			name:   "-c по нескольким файлам",
+			args:   []string{"-c", "ERROR", first, second},
+			stdout: first + ":2\n" + second + ":0\n",
+		},
+		{
+			name:   "--count-matches считает вхождения",
+			args:   []string{"--count-matches", "ERROR", first},
+			stdout: "3\n",
+		},
+		{
+			name:   "-v -c считает несовпавшие строки",
+			args:   []string{"-v", "-c", "ERROR", first},
+			stdout: "3\n",
+		},
+		{
+			name:   "-c с -m",
+			args:   []string{"-c", "-m", "1", "ERROR", first},
+			stdout: "1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			run(tt.args, &stdout, &stderr)
+			if stdout.String() != tt.stdout {
+				t.Errorf("stdout %q, ожидался %q (stderr: %q)", stdout.String(), tt.stdout, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunStats(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logFile, []byte("ERROR a ERROR\nok\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--stats", "ERROR", logFile}, &stdout, &stderr); code != exitMatch {
+		t.Fatalf("код завершения %d, stderr: %s", code, stderr.String())
+	}
+	for _, want := range []string{
+		"ERROR a ERROR\n",
+		"\n2 matches\n1 matched lines\n1 files contained matches\n1 files searched\n14 bytes printed\n17 bytes searched\n",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("в выводе нет %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestRunLocationMetadata(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	if err := os.WriteFile(first, []byte("ok\nfoo ERROR\nERROR bar ERROR\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("ERROR\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdout string
+	}{
+		{
+			name:   "-b печатает смещение строки",
+			args:   []string{"-b", "ERROR", first},
+			stdout: "3:foo ERROR\n13:ERROR bar ERROR\n",
+		},
+		{
+			name:   "-b -o печатает смещение вхождения",
+			args:   []string{"-b", "-o", "ERROR", first},
+			stdout: "7:ERROR\n13:ERROR\n23:ERROR\n",
+		},
+		{
+			name:   "-n -b",
+			args:   []string{"-n", "--byte-offset", "bar", first},
+			stdout: "3:13:ERROR bar ERROR\n",
+		},
+		{
+			name:   "-Z завершает имя файла нулевым байтом",
+			args:   []string{"-Z", "-c", "ERROR", first, second},
+			stdout: first + "\x002\n" + second + "\x001\n",
+		},
+		{
+			name:   "-T выравнивает по табуляции",
+			args:   []string{"-T", "-n", "bar", first},
+			stdout: "   3:\tERROR bar ERROR\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			run(tt.args, &stdout, &stderr)
+			if stdout.String() != tt.stdout {
+				t.Errorf("stdout %q, ожидался %q (stderr: %q)", stdout.String(), tt.stdout, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunLabelNamesStdin(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(input, []byte("ERROR from pipe\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	stdin := os.Stdin
+	os.Stdin = file
+	defer func() { os.Stdin = stdin }()
+
+	var stdout, stderr bytes.Buffer
+	run([]string{"--label", "pipe", "-c", "ERROR", "-", os.DevNull}, &stdout, &stderr)
+	if want := "pipe:1\n" + os.DevNull + ":0\n"; stdout.String() != want {
+		t.Errorf("stdout %q, ожидался %q (stderr: %q)", stdout.String(), want, stderr.String())
+	}
+}
+
+func TestRunIgnoreYo(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "names.txt")
+	if err := os.WriteFile(input, []byte("Фёдор\nФедор\nФиодор\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"-F", "-i", "--ignore-yo", "-n", "федор", input},
+		{"-i", "--ignore-yo", "-n", "^ф[ё]дор$", input},
+	} {
+		var stdout, stderr bytes.Buffer
+		run(args, &stdout, &stderr)
+		if want := "1:Фёдор\n2:Федор\n"; stdout.String() != want {
+			t.Errorf("%v: вывод %q, ожидался %q (stderr: %q)", args, stdout.String(), want, stderr.String())
+		}
+	}
+}
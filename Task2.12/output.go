@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+// matchColors - цвета подсветки; паттерны раскрашиваются по кругу, чтобы было видно, какой из них совпал
+var matchColors = []string{"01;31", "01;32", "01;33", "01;34", "01;35", "01;36"}
+
+// highlight оборачивает текст в ANSI-последовательность цвета паттерна
+func highlight(text string, pattern int) string {
+	return "\x1b[" + matchColors[pattern%len(matchColors)] + "m" + text + "\x1b[0m"
+}
+
+// colorize подсвечивает вхождения в строке
+func colorize(line string, spans []grep.Span) string {
+	var b strings.Builder
+	prev := 0
+	for _, s := range spans {
+		b.WriteString(line[prev:s.Start])
+		b.WriteString(highlight(line[s.Start:s.End], s.Pattern))
+		prev = s.End
+	}
+	b.WriteString(line[prev:])
+	return b.String()
+}
+
+// Минимальная ширина номера строки и смещения при -T
+const (
+	lineNumberWidth = 4
+	byteOffsetWidth = 9
+)
+
+// fileNamePrefix возвращает имя файла с разделителем: ':' или нулевым байтом при -Z
+func fileNamePrefix(name string, params *SearchParams) string {
+	if params.nullAfterName {
+		return name + "\x00"
+	}
+	return name + ":"
+}
+
+// textPrinter - вывод в формате grep: [имя:][номер:][смещение:]строка
+type textPrinter struct {
+	params  *SearchParams
+	prefix  string // имя файла с разделителем, если его нужно выводить
+	write   func(string)
+	printed int64
+}
+
+func newTextPrinter(params *SearchParams, prefix string, write func(string)) *textPrinter {
+	return &textPrinter{params: params, prefix: prefix, write: write}
+}
+
+// linePrefix собирает метаданные строки: имя файла, номер строки и смещение в байтах
+func (p *textPrinter) linePrefix(lineNum int, offset int64) string {
+	prefix := p.prefix
+	if p.params.lineNumber {
+		if p.params.initialTab {
+			prefix += fmt.Sprintf("%*d:", lineNumberWidth, lineNum)
+		} else {
+			prefix += strconv.Itoa(lineNum) + ":"
+		}
+	}
+	if p.params.byteOffset {
+		if p.params.initialTab {
+			prefix += fmt.Sprintf("%*d:", byteOffsetWidth, offset)
+		} else {
+			prefix += strconv.FormatInt(offset, 10) + ":"
+		}
+	}
+	// С -T содержимое строки начинается с позиции табуляции
+	if p.params.initialTab && prefix != "" {
+		prefix += "\t"
+	}
+	return prefix
+}
+
+// printLine форматирует строку результата с учётом -o, -n, -b и подсветки
+func (p *textPrinter) printLine(m grep.Match) {
+	// С -o печатаются только совпавшие части, без контекста;
+	// смещение при -b указывается для каждого вхождения
+	if p.params.onlyMatching {
+		if m.Context || p.params.invertMatch {
+			return
+		}
+		for i, sp := range m.Submatches {
+			part := m.Line[sp.Start:sp.End]
+			if p.params.replace {
+				part = m.Replacements[i]
+			}
+			if p.params.color {
+				part = highlight(part, sp.Pattern)
+			}
+			prefix := p.linePrefix(m.LineNumber, m.Offset+int64(sp.Start))
+			// При --fuzzy перед вхождением печатается число правок
+			if p.params.fuzzy > 0 {
+				prefix += strconv.Itoa(sp.Distance) + ":"
+			}
+			p.emit(prefix + part)
+		}
+		return
+	}
+
+	line := m.Line
+	switch {
+	case p.params.replace && !m.Context && !p.params.invertMatch:
+		line = replaceSpans(line, m.Submatches, m.Replacements, p.params.color)
+	case p.params.color && !m.Context && !p.params.invertMatch:
+		line = colorize(line, m.Submatches)
+	}
+	p.emit(p.linePrefix(m.LineNumber, m.Offset) + line)
+}
+
+func (p *textPrinter) emit(line string) {
+	p.printed += int64(len(line)) + 1
+	p.write(line)
+}
+
+func (p *textPrinter) finish(stats *searchStats) {
+	stats.bytesPrinted = p.printed
+}
+
+// writeStats печатает итоговую статистику --stats
+func writeStats(w io.Writer, stats searchStats, elapsed time.Duration) error {
+	_, err := fmt.Fprintf(w, "\n%d matches\n%d matched lines\n%d files contained matches\n%d files searched\n"+
+		"%d bytes printed\n%d bytes searched\n%.6f seconds spent searching\n%.6f seconds total\n",
+		stats.matches, stats.matchedLines, stats.searchesWithMatch, stats.searches,
+		stats.bytesPrinted, stats.bytesSearched, stats.elapsed.Seconds(), elapsed.Seconds())
+	return err
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// stdinName - имя, под которым в выводе фигурирует стандартный ввод
+const stdinName = "(standard input)"
+
+// errIsDirectory - ошибка для каталога, переданного без -r
+var errIsDirectory = errors.New("это каталог")
+
+// errInputIsOutput - ошибка для операнда, в который пишутся результаты поиска
+var errInputIsOutput = errors.New("входной файл совпадает с файлом вывода")
+
+// source - файл для поиска или ошибка, возникшая при обходе каталогов
+type source struct {
+	path string
+	err  error
+}
+
+// fileResult - результат поиска по одному файлу
+type fileResult struct {
+	name    string
+	output  []byte
+	matched bool
+	stats   searchStats
+	err     error
+}
+
+// searchJob - файл в работе; результат приходит в done
+type searchJob struct {
+	src  source
+	done chan fileResult
+}
+
+// walkOptions - параметры обхода каталогов
+type walkOptions struct {
+	recursive bool
+	noIgnore  bool // не читать .gitignore, .ignore и .grepignore
+	hidden    bool // обходить скрытые файлы и каталоги
+	// output - файл, в который пишутся результаты; при обходе он пропускается,
+	// иначе grep читал бы собственный вывод. nil, если вывод не в обычный файл
+	output os.FileInfo
+	// debug получает сообщения о пропущенных путях; может быть nil
+	debug func(msg string)
+}
+
+// walkSources перечисляет файлы для поиска в детерминированном порядке: операнды
+// в порядке перечисления, содержимое каталогов при -r - в лексикографическом.
+// Внутри каталогов пропускаются скрытые и исключённые файлами исключений пути;
+// явно указанные операнды ищутся всегда.
+func walkSources(ctx context.Context, operands []string, opts walkOptions) <-chan source {
+	sources := make(chan source)
+
+	go func() {
+		defer close(sources)
+		send := func(src source) bool {
+			select {
+			case sources <- src:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, operand := range operands {
+			if operand == "-" {
+				if !send(source{path: stdinName}) {
+					return
+				}
+				continue
+			}
+
+			info, err := os.Stat(operand)
+			if err == nil && opts.isOutput(info) {
+				err = errInputIsOutput
+			}
+			if err != nil || !info.IsDir() {
+				if !send(source{path: operand, err: err}) {
+					return
+				}
+				continue
+			}
+			if !opts.recursive {
+				if !send(source{path: operand, err: errIsDirectory}) {
+					return
+				}
+				continue
+			}
+
+			if !walkDir(operand, nil, opts, send) {
+				return
+			}
+		}
+	}()
+
+	return sources
+}
+
+// walkDir рекурсивно перечисляет файлы каталога dir; rules - правила исключения
+// родительских каталогов. Возвращает false, если обход нужно прекратить.
+func walkDir(dir string, rules []ignoreRule, opts walkOptions, send func(source) bool) bool {
+	if !opts.noIgnore {
+		dirRules, err := readIgnoreFiles(dir)
+		if err != nil && !send(source{path: dir, err: err}) {
+			return false
+		}
+		// Копия не даёт соседним каталогам дописывать правила в общий массив
+		rules = append(slices.Clip(rules), dirRules...)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return send(source{path: dir, err: err})
+	}
+	for _, entry := range entries {
+		name := filepath.Join(dir, entry.Name())
+		if !opts.hidden && isHidden(name) {
+			opts.skip(name, "скрытый путь")
+			continue
+		}
+		if rule := ignoredBy(rules, name, entry.IsDir()); rule != nil {
+			opts.skip(name, "правило "+rule.source+": "+rule.text)
+			continue
+		}
+
+		switch {
+		case entry.IsDir():
+			if !walkDir(name, rules, opts, send) {
+				return false
+			}
+		case entry.Type().IsRegular():
+			if opts.output != nil {
+				if info, err := entry.Info(); err == nil && opts.isOutput(info) {
+					opts.skip(name, "файл вывода")
+					continue
+				}
+			}
+			if !send(source{path: name}) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isOutput сообщает, что info - файл, в который пишутся результаты
+func (opts walkOptions) isOutput(info os.FileInfo) bool {
+	return opts.output != nil && os.SameFile(info, opts.output)
+}
+
+// skip сообщает о пропущенном пути при --debug
+func (opts walkOptions) skip(name, reason string) {
+	if opts.debug != nil {
+		opts.debug("пропущен " + name + ": " + reason)
+	}
+}
+
+// runSearch ищет по источникам в workers потоков. Файлы обрабатываются параллельно,
+// но результаты передаются в emit строго в порядке источников и целиком, поэтому
+// вывод разных файлов не перемешивается. Ошибка emit прекращает поиск.
+func runSearch(ctx context.Context, sources <-chan source, workers int, search func(context.Context, source) fileResult, emit func(fileResult) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan searchJob)
+	// pending хранит файлы в порядке вывода и ограничивает число файлов в работе
+	pending := make(chan searchJob, workers*4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				job.done <- search(ctx, job.src)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pending)
+		defer close(queue)
+		for src := range sources {
+			job := searchJob{src: src, done: make(chan fileResult, 1)}
+			select {
+			case pending <- job:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case queue <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var err error
+	for job := range pending {
+		// Готовый результат выводим и после отмены: select выбирает среди
+		// готовых веток случайно и мог бы потерять, например, ошибку файла при -q
+		select {
+		case res := <-job.done:
+			err = emit(res)
+		default:
+			select {
+			case res := <-job.done:
+				err = emit(res)
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	cancel()
+	// Дочитываем очередь, чтобы завершить горутину-распределитель
+	for range pending {
+	}
+	wg.Wait()
+	return err
+}
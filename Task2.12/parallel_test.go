@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+// makeLogTree создаёт каталог с files файлами по lines строк в каждом
+func makeLogTree(tb testing.TB, files, lines int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	for f := 0; f < files; f++ {
+		sub := filepath.Join(dir, fmt.Sprintf("day%02d", f%5))
+		if err := os.MkdirAll(sub, 0o755); err != nil {
+			tb.Fatal(err)
+		}
+		var b strings.Builder
+		for l := 0; l < lines; l++ {
+			level := "INFO"
+			if (l+f)%7 == 0 {
+				level = "ERROR"
+			}
+			fmt.Fprintf(&b, "2024-01-01T00:00:%02d %s request %d handled\n", l%60, level, l)
+		}
+		name := filepath.Join(sub, fmt.Sprintf("app%03d.log", f))
+		if err := os.WriteFile(name, []byte(b.String()), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// searchTree выполняет рекурсивный поиск и возвращает весь вывод
+func searchTree(tb testing.TB, dir string, workers int, params *SearchParams) string {
+	tb.Helper()
+	m, err := newMatcher([]string{"ERROR"}, params)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	searcher := grep.NewSearcher(m, params.options())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out bytes.Buffer
+	search := func(ctx context.Context, src source) fileResult {
+		return searchFile(ctx, src, searcher, params, true)
+	}
+	emit := func(res fileResult) error {
+		if res.err != nil {
+			return res.err
+		}
+		out.Write(res.output)
+		return nil
+	}
+	if err := runSearch(ctx, walkSources(ctx, []string{dir}, walkOptions{recursive: true}), workers, search, emit); err != nil {
+		tb.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestRunSearchKeepsFileOrder(t *testing.T) {
+	dir := makeLogTree(t, 40, 50)
+	params := &SearchParams{fixedString: true, lineNumber: true, contextLines: 1}
+
+	sequential := searchTree(t, dir, 1, params)
+	for _, workers := range []int{2, 8, 32} {
+		if got := searchTree(t, dir, workers, params); got != sequential {
+			t.Fatalf("вывод при %d потоках отличается от последовательного", workers)
+		}
+	}
+
+	// Файлы должны идти в лексикографическом порядке путей
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(sequential), "\n") {
+		name := line[:strings.Index(line, ":")]
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Fatalf("нарушен порядок файлов: %s перед %s", names[i-1], names[i])
+		}
+	}
+	if len(names) != 40 {
+		t.Fatalf("ожидалось 40 файлов в выводе, получили %d", len(names))
+	}
+}
+
+func TestWalkSourcesReportsErrorsInOrder(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.log")
+	if err := os.WriteFile(file, []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var got []source
+	for src := range walkSources(ctx, []string{filepath.Join(dir, "missing"), dir, "-"}, walkOptions{}) {
+		got = append(got, src)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("ожидалось 3 источника, получили %v", got)
+	}
+	if got[0].err == nil {
+		t.Error("для отсутствующего файла ожидалась ошибка")
+	}
+	if got[1].err != errIsDirectory {
+		t.Errorf("для каталога без -r ожидалась errIsDirectory, получили %v", got[1].err)
+	}
+	if got[2].path != stdinName {
+		t.Errorf("ожидался stdin, получили %q", got[2].path)
+	}
+}
+
+func TestRunRecursiveSkipsOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	var b strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, "ERROR %d\n", i)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.log"), []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	// Результаты пишутся в файл внутри обходимого каталога, как делает main
+	output, err := os.Create(outputFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	var stderr bytes.Buffer
+	if code := run([]string{"-r", "-j", "1", "ERROR", "."}, output, &stderr); code != exitMatch {
+		t.Fatalf("код завершения %d, stderr: %q", code, stderr.String())
+	}
+	got, err := os.ReadFile(outputFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "app.log:" + strings.ReplaceAll(strings.TrimSuffix(b.String(), "\n"), "\n", "\napp.log:") + "\n"
+	if string(got) != want {
+		t.Errorf("в выводе %d строк, ожидалось 2000; stderr: %q", strings.Count(string(got), "\n"), stderr.String())
+	}
+
+	// Явно указанный файл вывода не читается, а считается ошибкой
+	stderr.Reset()
+	if code := run([]string{"ERROR", outputFileName}, output, &stderr); code != exitTrouble {
+		t.Errorf("код завершения %d, ожидался %d", code, exitTrouble)
+	}
+	if !strings.Contains(stderr.String(), errInputIsOutput.Error()) {
+		t.Errorf("stderr %q", stderr.String())
+	}
+}
+
+func BenchmarkRunSearch(b *testing.B) {
+	dir := makeLogTree(b, 200, 2000)
+	params := &SearchParams{fixedString: true}
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			searchTree(b, dir, 1, params)
+		}
+	})
+	b.Run(fmt.Sprintf("parallel-%d", runtime.GOMAXPROCS(0)), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			searchTree(b, dir, runtime.GOMAXPROCS(0), params)
+		}
+	})
+}
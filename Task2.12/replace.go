@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+// errInPlaceTarget - --in-place переписывает только обычные файлы
+var errInPlaceTarget = errors.New("флаг --in-place нельзя применять к стандартному вводу и с --follow")
+
+// replaceSpans возвращает строку, где вхождения заменены текстом из replaced;
+// с color замены подсвечиваются цветом паттерна
+func replaceSpans(line string, spans []grep.Span, replaced []string, color bool) string {
+	var b strings.Builder
+	prev := 0
+	for i, sp := range spans {
+		b.WriteString(line[prev:sp.Start])
+		if color {
+			b.WriteString(highlight(replaced[i], sp.Pattern))
+		} else {
+			b.WriteString(replaced[i])
+		}
+		prev = sp.End
+	}
+	b.WriteString(line[prev:])
+	return b.String()
+}
+
+// rewriteFile заменяет вхождения в файле по правилам --replace и атомарно сохраняет
+// результат. Исходное содержимое остаётся в файле с суффиксом backup, если он задан.
+// Возвращает число изменённых строк.
+func rewriteFile(path string, m grep.Matcher, params *SearchParams, backup string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	// Двоичные файлы без -a не изменяются, как и не выводятся их строки
+	if params.binaryFiles != grep.BinaryFilesText && grep.IsBinary(data[:min(len(data), grep.BinaryCheckSize)], params.nullData) {
+		return 0, nil
+	}
+
+	opts := params.options()
+	terminator := string(opts.Terminator())
+	var b strings.Builder
+	b.Grow(len(data))
+	changed := 0
+	matches := 0
+	for rest := string(data); rest != ""; {
+		record := rest
+		if idx := strings.Index(rest, terminator); idx >= 0 {
+			record = rest[:idx+1]
+		}
+		rest = rest[len(record):]
+
+		line := opts.TrimRecord(record)
+		if (params.maxCount > 0 && matches >= params.maxCount) || !m.Match(line) {
+			b.WriteString(record)
+			continue
+		}
+		matches++
+		spans := m.FindAll(line)
+		replaced := replaceSpans(line, spans, grep.Replacements(m, params.replacement, line, spans), false)
+		if replaced != line {
+			changed++
+		}
+		b.WriteString(replaced)
+		b.WriteString(record[len(line):])
+	}
+
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, writeFileAtomic(path, []byte(b.String()), backup)
+}
+
+// writeFileAtomic записывает data во временный файл рядом с path и переименовывает
+// его поверх path, поэтому читатели видят либо старое, либо новое содержимое.
+// Резервная копия - жёсткая ссылка на исходный файл или его копия.
+func writeFileAtomic(path string, data []byte, backup string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// После успешного переименования временного файла уже нет, ошибку удаления игнорируем
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if backup != "" {
+		if err := backupFile(path, path+backup, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// backupFile сохраняет исходный файл под именем target
+func backupFile(path, target string, perm os.FileMode) error {
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(path, target); err == nil {
+		return nil
+	}
+	// Файловая система без жёстких ссылок: копируем содержимое
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, perm)
+}
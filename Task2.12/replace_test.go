@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunReplace(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	content := "user=alice id=42\nnothing\nuser=bob id=7\n"
+	if err := os.WriteFile(logFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"Номер и имя группы", []string{"-n", "--replace", "${name}#$2", `user=(?P<name>\w+) id=(\d+)`}, "1:alice#42\n3:bob#7\n"},
+		{"С -o печатаются только замены", []string{"-o", "--replace", "<$1>", `id=(\d+)`}, "<42>\n<7>\n"},
+		{"Группы нумеруются внутри каждого паттерна", []string{"-o", "--replace", "[$1]", "-e", `id=(\d+)`, "-e", `user=(\w+)`}, "[alice]\n[42]\n[bob]\n[7]\n"},
+		{"Пустая замена удаляет вхождения, контекст без изменений", []string{"-A", "1", "--replace", "", ` id=\d+`}, "user=alice\nnothing\nuser=bob\n"},
+		{"Фиксированная строка заменяется буквально", []string{"-F", "--replace", "$1", "alice"}, "user=$1 id=42\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(append(tt.args, logFile), &stdout, &stderr)
+			if code != exitMatch {
+				t.Fatalf("код завершения %d, stderr: %s", code, stderr.String())
+			}
+			if stdout.String() != tt.expected {
+				t.Errorf("вывод %q, ожидалось %q", stdout.String(), tt.expected)
+			}
+		})
+	}
+
+	// Без --in-place файл не меняется
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("файл изменён без --in-place: %q", data)
+	}
+}
+
+func TestRunReplaceInPlace(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	quietFile := filepath.Join(dir, "quiet.log")
+	content := "user=alice\r\nnothing\nuser=bob"
+	if err := os.WriteFile(logFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(quietFile, []byte("nothing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-q", "--in-place", "--backup", ".orig", "--replace", "U=$1", `user=(\w+)`, logFile, quietFile}, &stdout, &stderr)
+	if code != exitMatch {
+		t.Fatalf("код завершения %d, stderr: %s", code, stderr.String())
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "U=alice\r\nnothing\nU=bob"; string(data) != want {
+		t.Errorf("содержимое после замены %q, ожидалось %q", data, want)
+	}
+	info, err := os.Stat(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("права файла %v, ожидалось 0600", info.Mode().Perm())
+	}
+	backup, err := os.ReadFile(logFile + ".orig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != content {
+		t.Errorf("резервная копия %q, ожидалось %q", backup, content)
+	}
+	if _, err := os.Stat(quietFile + ".orig"); !os.IsNotExist(err) {
+		t.Error("для файла без совпадений не должна создаваться резервная копия")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("в каталоге остались лишние файлы: %v", entries)
+	}
+
+	for _, args := range [][]string{
+		{"--in-place", "x", logFile},
+		{"--in-place", "--replace", "y", "x", "-"},
+		{"--in-place", "--replace", "y", "-v", "x", logFile},
+	} {
+		if code := run(args, &stdout, &stderr); code != exitTrouble {
+			t.Errorf("%v: код %d, ожидался %d", args, code, exitTrouble)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/PavelBradnitski/WbTechL2/Task2.12/grep"
+)
+
+// searchStats - статистика поиска по одному или нескольким файлам
+type searchStats struct {
+	elapsed           time.Duration
+	searches          int
+	searchesWithMatch int
+	bytesSearched     int64
+	bytesPrinted      int64
+	matchedLines      int
+	matches           int // число отдельных вхождений
+}
+
+// add прибавляет статистику другого поиска
+func (s *searchStats) add(other searchStats) {
+	s.elapsed += other.elapsed
+	s.searches += other.searches
+	s.searchesWithMatch += other.searchesWithMatch
+	s.bytesSearched += other.bytesSearched
+	s.bytesPrinted += other.bytesPrinted
+	s.matchedLines += other.matchedLines
+	s.matches += other.matches
+}
+
+// addFound прибавляет итоги поиска пакета grep
+func (s *searchStats) addFound(found grep.Stats) {
+	s.bytesSearched += found.BytesSearched
+	s.matchedLines += found.MatchedLines
+	s.matches += found.Matches
+	if found.MatchedLines > 0 {
+		s.searchesWithMatch = 1
+	}
+}
+
+// needSpans сообщает, нужны ли позиции вхождений: для -o, подсветки, --json, --replace и подсчёта вхождений
+func needSpans(params *SearchParams) bool {
+	return params.onlyMatching || params.color || params.json || params.replace || params.countMatches || params.stats
+}
+
+// linePrinter форматирует результат поиска по одному файлу
+type linePrinter interface {
+	printLine(m grep.Match)
+	// finish вызывается после поиска по файлу и дополняет статистику
+	finish(stats *searchStats)
+}
+
+// recordTerminator возвращает разделитель записей вывода: перевод строки или нулевой байт при -z
+func recordTerminator(params *SearchParams) byte {
+	if params.nullData {
+		return 0
+	}
+	return '\n'
+}
+
+// newPrinter создаёт форматирование результата по параметрам вывода
+func newPrinter(name string, params *SearchParams, withName bool, out *bytes.Buffer) linePrinter {
+	if params.json {
+		return newJSONPrinter(name, params, out)
+	}
+	prefix := ""
+	if withName {
+		prefix = fileNamePrefix(name, params)
+	}
+	terminator := recordTerminator(params)
+	return newTextPrinter(params, prefix, func(line string) {
+		out.WriteString(line)
+		out.WriteByte(terminator)
+	})
+}
+
+// searchReader ищет по строкам r, пишет результат в out и возвращает статистику поиска.
+// Поиск прерывается, если ctx отменён.
+func searchReader(ctx context.Context, r io.Reader, name string, searcher *grep.Searcher, params *SearchParams, withName bool, out *bytes.Buffer) (searchStats, error) {
+	started := time.Now()
+	stats := searchStats{searches: 1}
+
+	printer := newPrinter(name, params, withName, out)
+	found, err := searcher.Search(ctx, r, func(m grep.Match) error {
+		printer.printLine(m)
+		return nil
+	})
+	// Ввод не удалось даже начать читать (например, повреждённый архив)
+	if err != nil && found.BytesSearched == 0 {
+		return stats, err
+	}
+	stats.addFound(found)
+
+	switch {
+	case params.quiet:
+	case params.countOnly:
+		prefix := ""
+		if withName {
+			prefix = fileNamePrefix(name, params)
+		}
+		out.WriteString(prefix + strconv.Itoa(countValue(stats, params)) + "\n")
+	case found.Binary && found.MatchedLines > 0 && !params.json:
+		out.WriteString("Binary file " + name + " matches\n")
+	}
+
+	stats.elapsed = time.Since(started)
+	printer.finish(&stats)
+	return stats, err
+}
+
+// countValue возвращает число для -c: выбранные строки или вхождения при --count-matches
+func countValue(stats searchStats, params *SearchParams) int {
+	// С -v вхождений в выбранных строках нет, поэтому считаются строки
+	if params.countMatches && !params.invertMatch {
+		return stats.matches
+	}
+	return stats.matchedLines
+}
+
+// searchFile открывает файл (или stdin для "-") и ищет по нему
+func searchFile(ctx context.Context, src source, searcher *grep.Searcher, params *SearchParams, withName bool) fileResult {
+	res := fileResult{name: src.path, err: src.err}
+	if res.err != nil {
+		return res
+	}
+
+	var out bytes.Buffer
+	if src.path == stdinName {
+		if params.label != "" {
+			res.name = params.label
+		}
+		res.stats, res.err = searchReader(ctx, os.Stdin, res.name, searcher, params, withName, &out)
+	} else {
+		file, err := os.Open(src.path)
+		if err != nil {
+			res.err = err
+			return res
+		}
+		res.stats, res.err = searchReader(ctx, file, src.path, searcher, params, withName, &out)
+		file.Close()
+	}
+	res.output = out.Bytes()
+	res.matched = res.stats.searchesWithMatch > 0
+	return res
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+	"github.com/PavelBradnitski/WbTechL2/field/fieldtest"
+)
+
+// TestFieldConformance прогоняет общий набор случаев через cut -f N:
+// колонка N должна совпадать с тем, что ожидают пакет field и sort -k N.
+// Строку без разделителя cut, как GNU cut, выводит целиком при любом N.
+func TestFieldConformance(t *testing.T) {
+	for _, c := range fieldtest.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			options := cutOptions{
+				delimiter:   c.Splitter.Delimiter,
+				whitespace:  c.Splitter.Whitespace,
+				delimiterRe: c.Splitter.Regexp,
+				csv:         c.Splitter.CSV,
+				format:      formatNDJSON,
+			}
+
+			for n := 1; n <= len(c.Fields)+1; n++ {
+				options.fields = fmt.Sprint(n)
+				fields, err := field.ParseList(options.fields)
+				if err != nil {
+					t.Fatal(err)
+				}
+				out, err := runCut(c.Line+"\n", options, fields, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var got []string
+				if err := json.Unmarshal([]byte(out), &got); err != nil {
+					t.Fatalf("-f %d: некорректный вывод %q: %v", n, out, err)
+				}
+				var expected []string
+				switch {
+				case !c.Splitter.HasDelimiter([]byte(c.Line)):
+					expected = []string{c.Line}
+				case n <= len(c.Fields):
+					expected = []string{c.Column(n)}
+				}
+				if !slices.Equal(got, expected) {
+					t.Errorf("-f %d: получили %q, ожидалось %q", n, got, expected)
+				}
+			}
+		})
+	}
+}
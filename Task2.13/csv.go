@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+// cutCSV читает записи CSV/TSV из r по RFC 4180 (поля в кавычках могут
+// содержать разделитель и переводы строк) и передаёт выбранные поля в out.
+// С --header номера колонок определяются по первой записи; с --reorder поля
+// выводятся в порядке order.
+func cutCSV(r io.Reader, out recordWriter, options cutOptions, fields field.List, order field.Order) error {
+	reader, err := options.splitter().NewCSVReader(r)
+	if err != nil {
+		return err
+	}
+	reader.ReuseRecord = true
+
+	var selected []string
+	var indexes []int
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("запись %d: %w", n, err)
+		}
+		isHeader := n == 1 && options.header
+		if isHeader {
+			if fields, order, err = resolveFields(options, record); err != nil {
+				return fmt.Errorf("заголовок: %w", err)
+			}
+		}
+		// запись из одного поля (без разделителя) выводится целиком, а с -s пропускается
+		selected = selected[:0]
+		switch {
+		case len(record) < 2 && options.separated:
+			continue
+		case len(record) < 2:
+			selected = append(selected, record...)
+		case options.reorder:
+			indexes = order.Indexes(indexes[:0], len(record))
+			for _, i := range indexes {
+				selected = append(selected, record[i])
+			}
+		default:
+			selected = selectRecord(selected, record, fields, options.complement)
+		}
+		if isHeader {
+			err = out.WriteHeader(selected)
+		} else {
+			err = out.Write(selected)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectRecord добавляет в dst выбранные поля записи в порядке их следования
+func selectRecord(dst, record []string, fields field.List, complement bool) []string {
+	for i, value := range record {
+		if fields.Includes(i+1) != complement {
+			dst = append(dst, value)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+func TestCutCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  cutOptions
+		list     string
+		expected string
+	}{
+		{
+			name:     "запятая внутри кавычек",
+			input:    "id,name,city\n1,\"Smith, John\",Berlin\n",
+			options:  cutOptions{delimiter: ","},
+			list:     "2",
+			expected: "name\n\"Smith, John\"\n",
+		},
+		{
+			name:     "многострочная запись и экранированные кавычки",
+			input:    "1,\"line one\nline two\",x\n2,\"say \"\"hi\"\"\",y\n",
+			options:  cutOptions{delimiter: ","},
+			list:     "2-",
+			expected: "\"line one\nline two\",x\n\"say \"\"hi\"\"\",y\n",
+		},
+		{
+			name:     "--complement и пустые поля",
+			input:    "a,,c,d\n",
+			options:  cutOptions{delimiter: ",", complement: true},
+			list:     "3",
+			expected: "a,,d\n",
+		},
+		{
+			name:     "TSV с кавычками",
+			input:    "a\t\"b\tb\"\tc\n",
+			options:  cutOptions{delimiter: "\t", tsv: true},
+			list:     "2",
+			expected: "\"b\tb\"\n",
+		},
+		{
+			name:     "--output-delimiter перекодирует в TSV",
+			input:    "a,\"b,c\",d\n",
+			options:  cutOptions{delimiter: ",", outputDelimiter: "\t"},
+			list:     "1-2",
+			expected: "a\tb,c\n",
+		},
+		{
+			name:     "-s пропускает записи из одного поля",
+			input:    "header\na,b\n",
+			options:  cutOptions{delimiter: ",", separated: true},
+			list:     "2",
+			expected: "b\n",
+		},
+		{
+			name:     "--lazy-quotes",
+			input:    "a,b \"c\" d,e\n",
+			options:  cutOptions{delimiter: ",", lazyQuotes: true},
+			list:     "2",
+			expected: "\"b \"\"c\"\" d\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := field.ParseList(tt.list)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.options.csv = !tt.options.tsv
+			out, err := runCut(tt.input, tt.options, fields, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.expected {
+				t.Errorf("получили %q, ожидалось %q", out, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCutCSVErrors(t *testing.T) {
+	fields, _ := field.ParseList("1")
+
+	_, err := runCut("a,b\nc,d\ne,f \"g\" h\n", cutOptions{delimiter: ",", csv: true}, fields, nil)
+	if err == nil || !strings.Contains(err.Error(), "запись 3") {
+		t.Errorf("ожидалась ошибка с номером записи 3, получили %v", err)
+	}
+
+	for _, delimiter := range []string{"::", "\"", "\n", ""} {
+		if _, err := runCut("a\n", cutOptions{delimiter: delimiter, csv: true}, fields, nil); err == nil {
+			t.Errorf("для разделителя %q ожидалась ошибка", delimiter)
+		}
+	}
+}
+
+func TestValidateCSVOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options cutOptions
+		wantErr bool
+	}{
+		{name: "--csv с -f", options: cutOptions{fields: "1", csv: true, lazyQuotes: true}},
+		{name: "--csv и --tsv", options: cutOptions{fields: "1", csv: true, tsv: true}, wantErr: true},
+		{name: "--csv с -c", options: cutOptions{chars: "1", csv: true}, wantErr: true},
+		{name: "--tsv с --whitespace", options: cutOptions{fields: "1", tsv: true, whitespace: true}, wantErr: true},
+		{name: "--lazy-quotes без --csv", options: cutOptions{fields: "1", lazyQuotes: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOptions(tt.options, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ошибка %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+func TestSelectFieldsRegexDelimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		options  cutOptions
+		expected string
+	}{
+		{
+			name:     "разные разделители с пробелами",
+			line:     "alpha ; beta|gamma  ;delta",
+			options:  cutOptions{fields: "2,4", delimiterRe: regexp.MustCompile(`\s*[;|]\s*`)},
+			expected: "beta delta",
+		},
+		{
+			name:     "вывод df через группы пробелов",
+			line:     "/dev/sda1       41152736 30112452   9026740  77% /",
+			options:  cutOptions{fields: "1,5-", delimiterRe: regexp.MustCompile(` +`), outputDelimiter: ","},
+			expected: "/dev/sda1,77%,/",
+		},
+		{
+			name:     "ведущий разделитель даёт пустое первое поле",
+			line:     "  1234 pts/0",
+			options:  cutOptions{fields: "2", delimiterRe: regexp.MustCompile(` +`)},
+			expected: "1234",
+		},
+		{
+			name:     "-s с регулярным выражением",
+			line:     "no-separators-here",
+			options:  cutOptions{fields: "1", delimiterRe: regexp.MustCompile(`[;|]`), separated: true},
+			expected: "",
+		},
+		{
+			name:     "--complement",
+			line:     "a1b22c333d",
+			options:  cutOptions{fields: "2", delimiterRe: regexp.MustCompile(`\d+`), complement: true},
+			expected: "a c d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := field.ParseList(tt.options.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := cutLine(tt.line, tt.options, fields, nil)
+			if result != tt.expected {
+				t.Errorf("ожидалось %q, получили %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestValidateRegexDelimiterOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options cutOptions
+		wantErr bool
+	}{
+		{name: "-E с -f", options: cutOptions{fields: "1", regexDelimiter: true}},
+		{name: "-E с -c", options: cutOptions{chars: "1", regexDelimiter: true}, wantErr: true},
+		{name: "-E с --csv", options: cutOptions{fields: "1", regexDelimiter: true, csv: true}, wantErr: true},
+		{name: "-E с --whitespace", options: cutOptions{fields: "1", regexDelimiter: true, whitespace: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOptions(tt.options, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ошибка %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/PavelBradnitski/WbTechL2/Task2.13
+
+go 1.24.1
+
+require github.com/PavelBradnitski/WbTechL2/field v0.0.0
+
+replace github.com/PavelBradnitski/WbTechL2/field => ../field
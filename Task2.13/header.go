@@ -0,0 +1,30 @@
+package main
+
+import "github.com/PavelBradnitski/WbTechL2/field"
+
+// resolveFields разбирает список из -f, -b, -c или -F. header - поля первой
+// строки входа при --header (иначе nil): по нему имена колонок заменяются
+// номерами. Возвращает список для вывода в порядке строки и порядок запроса
+// для --reorder.
+func resolveFields(options cutOptions, header []string) (field.List, field.Order, error) {
+	list, namesOnly := options.list(), false
+	if options.names != "" {
+		list, namesOnly = options.names, true
+	}
+	order, err := field.ParseItems(list, header, namesOnly)
+	if err != nil {
+		return nil, nil, err
+	}
+	return field.Merge(order), order, nil
+}
+
+// splitFields разбивает строку на поля по тем же правилам, что и lineCutter
+func splitFields(line string, options cutOptions) []string {
+	// текстовый ввод разбирается без CSV, поэтому ошибки быть не может
+	split, _ := options.splitter().Split(nil, []byte(line), 0)
+	parts := make([]string, len(split))
+	for i, part := range split {
+		parts[i] = string(part)
+	}
+	return parts
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+func TestResolveFields(t *testing.T) {
+	header := []string{"id", "name", "email", "created-at"}
+	tests := []struct {
+		name     string
+		options  cutOptions
+		fields   field.List
+		order    field.Order
+		hasError string
+	}{
+		{
+			name:    "-F по именам",
+			options: cutOptions{names: "email,id"},
+			fields:  field.List{{Start: 1, End: 1}, {Start: 3, End: 3}},
+			order:   field.Order{{Start: 3, End: 3}, {Start: 1, End: 1}},
+		},
+		{
+			name:    "имя с дефисом не считается диапазоном",
+			options: cutOptions{fields: "created-at,2-3"},
+			fields:  field.List{{Start: 2, End: 4}},
+			order:   field.Order{{Start: 4, End: 4}, {Start: 2, End: 3}},
+		},
+		{
+			name:     "-F не принимает номера",
+			options:  cutOptions{names: "name,2"},
+			hasError: "доступны: id, name, email, created-at",
+		},
+		{
+			name:     "неизвестное имя в -f",
+			options:  cutOptions{fields: "phone"},
+			hasError: "доступны колонки: id, name, email, created-at",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, order, err := resolveFields(tt.options, header)
+			if tt.hasError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.hasError) {
+					t.Fatalf("ожидалась ошибка с %q, получили %v", tt.hasError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) || !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("получили %v и %v, ожидалось %v и %v", fields, order, tt.fields, tt.order)
+			}
+		})
+	}
+}
+
+func TestReorderFields(t *testing.T) {
+	options := cutOptions{delimiter: ",", reorder: true}
+	order := field.Order{{Start: 3, End: 3}, {Start: 1, End: 1}, {Start: 5, End: field.OpenEnd}}
+
+	if got := cutLine("a,b,c,d,e,f", options, nil, order); got != "c,a,e,f" {
+		t.Errorf("получили %q", got)
+	}
+	if got := cutLine("a,b", options, nil, order); got != "a" {
+		t.Errorf("для короткой строки получили %q", got)
+	}
+}
+
+func TestCutCSVHeader(t *testing.T) {
+	input := "id,name,email\n1,\"Doe, Jane\",jane@example.com\n"
+	tests := []struct {
+		name     string
+		options  cutOptions
+		expected string
+	}{
+		{
+			name:     "заголовок выводится с выбранными колонками",
+			options:  cutOptions{delimiter: ",", names: "email,name", header: true},
+			expected: "name,email\n\"Doe, Jane\",jane@example.com\n",
+		},
+		{
+			name:     "--reorder",
+			options:  cutOptions{delimiter: ",", names: "email,name", header: true, reorder: true},
+			expected: "email,name\njane@example.com,\"Doe, Jane\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.csv = true
+			out, err := runCut(input, tt.options, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.expected {
+				t.Errorf("получили %q, ожидалось %q", out, tt.expected)
+			}
+		})
+	}
+
+	_, err := runCut(input, cutOptions{delimiter: ",", names: "phone", header: true, csv: true}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "id, name, email") {
+		t.Errorf("ожидалась ошибка со списком колонок, получили %v", err)
+	}
+}
+
+func TestValidateHeaderOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options cutOptions
+		wantErr bool
+	}{
+		{name: "-F", options: cutOptions{names: "id", header: true, reorder: true}},
+		{name: "-f с --header", options: cutOptions{fields: "id", header: true}},
+		{name: "-F и -f", options: cutOptions{names: "id", fields: "1", header: true}, wantErr: true},
+		{name: "--header с -c", options: cutOptions{chars: "1", header: true}, wantErr: true},
+		{name: "--reorder с --complement", options: cutOptions{fields: "1", reorder: true, complement: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOptions(tt.options, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ошибка %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+// cutFiles обрабатывает файлы по порядку; "-" и пустой список означают stdin.
+// Ошибка файла выводится в errOut и не прерывает обработку остальных;
+// возвращает false, если хотя бы один файл обработать не удалось.
+// Вывод всех файлов идёт в один out, который в конце сбрасывается.
+func cutFiles(names []string, out recordWriter, errOut io.Writer, options cutOptions, fields field.List, order field.Order) bool {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	ok := true
+	for _, name := range names {
+		if err := cutFile(name, out, options, fields, order); err != nil {
+			// имя файла уже выводится, поэтому из ошибки открытия берём только причину
+			var pathErr *os.PathError
+			if errors.As(err, &pathErr) {
+				err = pathErr.Err
+			}
+			fmt.Fprintf(errOut, "%s: %v\n", name, err)
+			ok = false
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(errOut, "ошибка записи: %v\n", err)
+		ok = false
+	}
+	return ok
+}
+
+// cutFile открывает файл (или stdin для "-") и выводит выбранные части записей
+func cutFile(name string, out recordWriter, options cutOptions, fields field.List, order field.Order) error {
+	if name == "-" {
+		return cutInput(os.Stdin, out, options, fields, order)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return cutInput(f, out, options, fields, order)
+}
+
+// cutInput выбирает режим разбора: CSV/TSV или построчный
+func cutInput(r io.Reader, out recordWriter, options cutOptions, fields field.List, order field.Order) error {
+	if options.csv || options.tsv {
+		return cutCSV(r, out, options, fields, order)
+	}
+	return cutText(r, out, options, fields, order)
+}
+
+// cutText читает записи, разделённые переводом строки (или NUL с -z), без
+// ограничения на длину записи. С --header номера колонок определяются по
+// первой записи, а сама она передаётся в out как заголовок. Текстовый вывод
+// собирается прямо в буфере writer'а, с -j - пачками в нескольких потоках.
+func cutText(r io.Reader, out recordWriter, options cutOptions, fields field.List, order field.Order) error {
+	records := newRecordReader(r, options.terminator())
+
+	line, err := records.next()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if options.header {
+		if fields, order, err = resolveFields(options, splitFields(string(line), options)); err != nil {
+			return fmt.Errorf("некорректный список полей: %w", err)
+		}
+	}
+	cutter := newLineCutter(options, fields, order)
+
+	tw, isText := out.(*textRecordWriter)
+	if !isText {
+		return cutRecords(records, line, out, cutter)
+	}
+
+	if err := tw.writeCut(cutter, line); err != nil {
+		return err
+	}
+	if options.workers > 1 {
+		return cutParallel(records, tw, options, fields, order)
+	}
+	for {
+		line, err := records.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := tw.writeCut(cutter, line); err != nil {
+			return err
+		}
+	}
+}
+
+// cutRecords передаёт выбранные поля записей в out по отдельности
+// (для --format=csv, json и ndjson); first - уже прочитанная первая запись
+func cutRecords(records *recordReader, first []byte, out recordWriter, cutter *lineCutter) error {
+	var record []string
+	line, isHeader := first, cutter.options.header
+	for {
+		if !cutter.options.separated || cutter.splitter.HasDelimiter(line) {
+			record = cutter.appendParts(record[:0], line)
+			var err error
+			if isHeader {
+				err = out.WriteHeader(record)
+			} else {
+				err = out.Write(record)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if line, err = records.next(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		isHeader = false
+	}
+}
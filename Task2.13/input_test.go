@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+// runCut прогоняет input через cutInput с writer'ом для options и возвращает вывод
+func runCut(input string, options cutOptions, fields field.List, order field.Order) (string, error) {
+	var buf bytes.Buffer
+	out, err := newRecordWriter(&buf, options)
+	if err != nil {
+		return "", err
+	}
+	if err := cutInput(strings.NewReader(input), out, options, fields, order); err != nil {
+		return buf.String(), err
+	}
+	err = out.Flush()
+	return buf.String(), err
+}
+
+func TestCutText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  cutOptions
+		expected string
+	}{
+		{
+			name:     "последняя строка без перевода строки",
+			input:    "a,b\nc,d",
+			options:  cutOptions{fields: "2", delimiter: ","},
+			expected: "b\nd\n",
+		},
+		{
+			name:     "-z разделяет записи NUL",
+			input:    "a,b\nx\x00c,d\x00",
+			options:  cutOptions{fields: "2", delimiter: ",", zeroTerminated: true},
+			expected: "b\nx\x00d\x00",
+		},
+		{
+			name:     "строка без разделителя выводится целиком",
+			input:    "nodelim\na,b\n",
+			options:  cutOptions{fields: "2", delimiter: ","},
+			expected: "nodelim\nb\n",
+		},
+		{
+			name:     "строка без разделителя с --complement",
+			input:    "nodelim\na,b\n",
+			options:  cutOptions{fields: "1", delimiter: ",", complement: true},
+			expected: "nodelim\nb\n",
+		},
+		{
+			name:     "-s пропускает строки без разделителя",
+			input:    "title\na,b\n",
+			options:  cutOptions{fields: "1", delimiter: ",", separated: true},
+			expected: "a\n",
+		},
+		{
+			name:     "--header",
+			input:    "id,name\n1,bob\n",
+			options:  cutOptions{names: "name", delimiter: ",", header: true},
+			expected: "name\nbob\n",
+		},
+		{
+			name:     "пустой вход",
+			input:    "",
+			options:  cutOptions{fields: "1", delimiter: ","},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields field.List
+			var order field.Order
+			if !tt.options.header {
+				var err error
+				if fields, order, err = resolveFields(tt.options, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			out, err := runCut(tt.input, tt.options, fields, order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.expected {
+				t.Errorf("получили %q, ожидалось %q", out, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCutTextLongLine(t *testing.T) {
+	// длиннее буфера bufio.Scanner по умолчанию (64 КБ)
+	long := strings.Repeat("x", 1<<20)
+	options := cutOptions{fields: "2", delimiter: ","}
+	fields, _ := field.ParseList(options.fields)
+
+	out, err := runCut("a,"+long+"\n", options, fields, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != len(long)+1 {
+		t.Errorf("длина вывода %d, ожидалось %d", len(out), len(long)+1)
+	}
+}
+
+func TestCutFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.csv")
+	second := filepath.Join(dir, "second.csv")
+	if err := os.WriteFile(first, []byte("a,1\nb,2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("c,3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.csv")
+
+	options := cutOptions{fields: "1", delimiter: ","}
+	fields, _ := field.ParseList(options.fields)
+
+	var out, errOut bytes.Buffer
+	writer, err := newRecordWriter(&out, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok := cutFiles([]string{first, missing, second}, writer, &errOut, options, fields, nil)
+	if ok {
+		t.Error("ожидалась неудача из-за отсутствующего файла")
+	}
+	if out.String() != "a\nb\nc\n" {
+		t.Errorf("получили %q: файлы после ошибки должны обрабатываться", out.String())
+	}
+	if !strings.HasPrefix(errOut.String(), missing+": ") {
+		t.Errorf("сообщение об ошибке %q должно начинаться с имени файла", errOut.String())
+	}
+}
@@ -1,142 +1,260 @@
 package main
 
 import (
-	"bufio"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"os"
-	"strconv"
-	"strings"
+	"regexp"
+	"runtime"
+	"slices"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
 )
 
 // cutOptions структура для хранения параметров командной строки
 type cutOptions struct {
-	fields    string
-	delimiter string
-	separated bool // если true, то выводить только строки с разделителем
+	fields     string
+	bytes      string // список позиций байтов (-b)
+	chars      string // список позиций символов (-c)
+	delimiter  string
+	separated  bool // если true, то выводить только строки с разделителем
+	complement bool // выводить все поля, кроме перечисленных
+	noSplit    bool // с -b не разрезать многобайтовые символы
+
+	outputDelimiter string // разделитель полей при выводе; по умолчанию входной
+	whitespace      bool   // поля разделяются группами пробелов и табуляций
+
+	csv        bool // разбирать вход как CSV (RFC 4180)
+	tsv        bool // разбирать вход как TSV с кавычками по правилам CSV
+	lazyQuotes bool // допускать кавычки внутри неэкранированных полей
+
+	names   string // имена колонок (-F); подразумевает --header
+	header  bool   // первая строка - заголовок с именами колонок
+	reorder bool   // выводить поля в порядке запроса, а не в порядке строки
+
+	zeroTerminated bool // записи разделяются NUL, а не переводом строки
+
+	regexDelimiter bool           // -d - регулярное выражение (-E, --regex-delimiter)
+	delimiterRe    *regexp.Regexp // скомпилированный -d при regexDelimiter
+
+	format     string // формат вывода: text, csv, json или ndjson
+	keys       string // имена колонок для JSON (--names)
+	inferTypes bool   // определять в JSON числа и true/false
+
+	workers int // число потоков обработки текстового ввода (-j)
 }
 
-func main() {
-	options := parseCommandLineFlags()
+// terminator возвращает байт, которым заканчиваются записи на входе и выходе
+func (o cutOptions) terminator() byte {
+	if o.zeroTerminated {
+		return 0
+	}
+	return '\n'
+}
 
-	scanner := bufio.NewScanner(os.Stdin)
+// outputSeparator возвращает разделитель, которым соединяются выбранные поля
+func (o cutOptions) outputSeparator() string {
+	switch {
+	case o.outputDelimiter != "":
+		return o.outputDelimiter
+	case o.whitespace, o.delimiterRe != nil:
+		// совпавшие разделители различаются, поэтому соединяем пробелом
+		return " "
+	}
+	return o.delimiter
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+// splitter возвращает правила разбиения записи на поля
+func (o cutOptions) splitter() field.Splitter {
+	return field.Splitter{
+		Delimiter:  o.delimiter,
+		Whitespace: o.whitespace,
+		Regexp:     o.delimiterRe,
+		CSV:        o.csv || o.tsv,
+		LazyQuotes: o.lazyQuotes,
+	}
+}
 
-		if options.separated && !strings.Contains(line, options.delimiter) {
-			continue
+// list возвращает список из того флага -f, -b или -c, который был задан
+func (o cutOptions) list() string {
+	switch {
+	case o.bytes != "":
+		return o.bytes
+	case o.chars != "":
+		return o.chars
+	}
+	return o.fields
+}
+
+func main() {
+	options := parseCommandLineFlags()
+
+	var fields field.List
+	var order field.Order
+	if options.list() != "" && !options.header {
+		var err error
+		fields, order, err = resolveFields(options, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "некорректный список позиций: %v\n", err)
+			os.Exit(1)
 		}
-		fieldsIdx := parseFieldsList(options.fields)
-		selectedLine := selectFields(line, options, fieldsIdx)
-		fmt.Println(selectedLine)
 	}
 
-	if err := scanner.Err(); err != nil && err != io.EOF {
-		fmt.Fprintf(os.Stderr, "ошибка чтения: %v\n", err)
+	out, err := newRecordWriter(os.Stdout, options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "некорректный формат вывода: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !cutFiles(flag.Args(), out, os.Stderr, options, fields, order) {
 		os.Exit(1)
 	}
 }
 
 func parseCommandLineFlags() cutOptions {
 	flagF := flag.String("f", "", "выбрать поля (колонки)")
+	flagB := flag.String("b", "", "выбрать байты")
+	flagC := flag.String("c", "", "выбрать символы (UTF-8)")
+	flagN := flag.Bool("n", false, "с -b не разрезать многобайтовые символы")
 	flagD := flag.String("d", "\t", "использовать другой разделитель")
 	flagS := flag.Bool("s", false, "только строки с разделителем")
+	flagOutputDelimiter := flag.String("output-delimiter", "", "разделитель полей при выводе")
+	flagWhitespace := flag.Bool("whitespace", false, "разделять поля группами пробелов и табуляций")
+	flagCSV := flag.Bool("csv", false, "разбирать вход как CSV (RFC 4180), разделитель по умолчанию - запятая")
+	flagTSV := flag.Bool("tsv", false, "разбирать вход как TSV с кавычками по правилам CSV")
+	flagLazyQuotes := flag.Bool("lazy-quotes", false, "с --csv/--tsv допускать некорректные кавычки")
+	flagNames := flag.String("F", "", "выбрать колонки по именам из заголовка (подразумевает --header)")
+	flagHeader := flag.Bool("header", false, "первая строка - заголовок; в -f можно указывать имена колонок")
+	flagReorder := flag.Bool("reorder", false, "выводить поля в порядке списка, а не в порядке строки")
+	var regexDelimiter bool
+	flag.BoolVar(&regexDelimiter, "E", false, "-d - регулярное выражение (краткая форма --regex-delimiter)")
+	flag.BoolVar(&regexDelimiter, "regex-delimiter", false, "-d - регулярное выражение")
+	flagFormat := flag.String("format", formatText, "формат вывода: text, csv, json или ndjson")
+	flagKeys := flag.String("names", "", "имена колонок для JSON через запятую")
+	flagInferTypes := flag.Bool("infer-types", false, "выводить в JSON числа и true/false без кавычек")
+	flagJ := flag.Int("j", 1, "число потоков обработки, 0 - по числу процессоров; порядок вывода сохраняется")
+	flagZ := flag.Bool("z", false, "записи разделяются NUL, а не переводом строки")
+	flagComplement := flag.Bool("complement", false, "выбрать все позиции, кроме перечисленных в -f, -b или -c")
 	flag.Parse()
 
-	if *flagF == "" && !*flagS {
-		fmt.Println("необходимо указать хотя бы один из флагов: -f или -s")
-		os.Exit(1)
-	}
-
 	options := cutOptions{
-		fields:    *flagF,
-		delimiter: *flagD,
-		separated: *flagS,
-	}
+		fields:     *flagF,
+		bytes:      *flagB,
+		chars:      *flagC,
+		delimiter:  *flagD,
+		separated:  *flagS,
+		complement: *flagComplement,
+		noSplit:    *flagN,
 
-	return options
-}
-func selectFields(line string, options cutOptions, fieldsIdx []int) string {
-	// если поля не заданы – возвращаем строку целиком
-	if len(fieldsIdx) == 0 {
-		return line
-	}
+		outputDelimiter: *flagOutputDelimiter,
+		whitespace:      *flagWhitespace,
 
-	// если включён separated, но в строке нет разделителя → пропускаем
-	if options.separated && !strings.Contains(line, options.delimiter) {
-		return ""
-	}
+		csv:        *flagCSV,
+		tsv:        *flagTSV,
+		lazyQuotes: *flagLazyQuotes,
 
-	// специальный случай для табуляции
-	if options.delimiter == "\t" {
-		fields := strings.Fields(line)
-		var b strings.Builder
-		for i, idx := range fieldsIdx {
-			if idx > 0 && idx <= len(fields) {
-				if i > 0 {
-					b.WriteByte(' ') // cut заменяет \t на пробел
-				}
-				b.WriteString(fields[idx-1])
-			}
-		}
-		return b.String()
+		names:   *flagNames,
+		header:  *flagHeader || *flagNames != "",
+		reorder: *flagReorder,
+
+		zeroTerminated: *flagZ,
+		regexDelimiter: regexDelimiter,
+
+		format:     *flagFormat,
+		keys:       *flagKeys,
+		inferTypes: *flagInferTypes,
+
+		workers: *flagJ,
+	}
+	if options.workers == 0 {
+		options.workers = runtime.GOMAXPROCS(0)
+	}
+	if options.csv && !explicitDelimiter() {
+		options.delimiter = ","
 	}
 
-	// оптимизация для 1-символьного разделителя (например, , ;)
-	if len(options.delimiter) == 1 {
-		return fastSelect(line, options.delimiter[0], fieldsIdx)
+	if err := validateOptions(options, explicitDelimiter()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	// общий случай (много-символьный разделитель)
-	fields := strings.Split(line, options.delimiter)
-	var b strings.Builder
-	for i, idx := range fieldsIdx {
-		if idx > 0 && idx <= len(fields) {
-			if i > 0 {
-				b.WriteString(options.delimiter)
-			}
-			b.WriteString(fields[idx-1])
+	if options.regexDelimiter {
+		re, err := field.CompileDelimiter(options.delimiter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "некорректный разделитель: %v\n", err)
+			os.Exit(1)
 		}
+		options.delimiterRe = re
 	}
-	return b.String()
+
+	return options
 }
 
-// оптимизированный вариант для 1-символьного разделителя
-func fastSelect(line string, delim byte, fieldsIdx []int) string {
-	var b strings.Builder
-	fieldStart := 0
-	fieldNum := 1
-	idxPos := 0
-
-	for i := 0; i <= len(line); i++ {
-		if i == len(line) || line[i] == delim {
-			if idxPos < len(fieldsIdx) && fieldsIdx[idxPos] == fieldNum {
-				if b.Len() > 0 {
-					b.WriteByte(delim)
-				}
-				b.WriteString(line[fieldStart:i])
-				idxPos++
-			}
-			fieldStart = i + 1
-			fieldNum++
+// explicitDelimiter сообщает, был ли -d указан в командной строке
+func explicitDelimiter() bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "d" {
+			set = true
 		}
-	}
-	return b.String()
+	})
+	return set
 }
 
-// parseFieldsList разбирает строку с номерами полей, разделенными запятыми, и возвращает слайс индексов
-func parseFieldsList(fieldsList string) []int {
-	fieldIndexes := make([]int, 0)
-	fields := strings.Split(fieldsList, ",")
-
-	for _, field := range fields {
-		idx, err := strconv.Atoi(field)
-		if err == nil && idx > 0 {
-			fieldIndexes = append(fieldIndexes, idx)
+// validateOptions проверяет сочетание флагов режимов -f, -b и -c
+func validateOptions(options cutOptions, delimiterSet bool) error {
+	modes := 0
+	for _, list := range []string{options.fields, options.bytes, options.chars, options.names} {
+		if list != "" {
+			modes++
 		}
 	}
-
-	return fieldIndexes
+	// режим полей: -f или -F
+	fieldMode := options.fields != "" || options.names != ""
+	switch {
+	case modes > 1:
+		return errors.New("можно указать только один из флагов: -b, -c, -f или -F")
+	case modes == 0 && !options.separated:
+		return errors.New("необходимо указать хотя бы один из флагов: -b, -c, -f, -F или -s")
+	case !fieldMode && modes > 0 && options.separated:
+		return errors.New("флаг -s имеет смысл только с -f")
+	case !fieldMode && modes > 0 && delimiterSet:
+		return errors.New("разделитель -d задаётся только с -f")
+	case !fieldMode && modes > 0 && options.outputDelimiter != "":
+		return errors.New("флаг --output-delimiter имеет смысл только с -f")
+	case options.header && !fieldMode:
+		return errors.New("флаг --header имеет смысл только с -f или -F")
+	case options.reorder && !fieldMode:
+		return errors.New("флаг --reorder имеет смысл только с -f или -F")
+	case options.reorder && options.complement:
+		return errors.New("флаги --reorder и --complement несовместимы")
+	case options.whitespace && delimiterSet:
+		return errors.New("флаги --whitespace и -d несовместимы")
+	case options.csv && options.tsv:
+		return errors.New("флаги --csv и --tsv несовместимы")
+	case (options.csv || options.tsv) && !fieldMode:
+		return errors.New("флаги --csv и --tsv работают только с -f или -F")
+	case (options.csv || options.tsv) && options.whitespace:
+		return errors.New("флаг --whitespace несовместим с --csv и --tsv")
+	case options.regexDelimiter && !fieldMode:
+		return errors.New("флаг --regex-delimiter имеет смысл только с -f или -F")
+	case options.regexDelimiter && (options.csv || options.tsv || options.whitespace):
+		return errors.New("флаг --regex-delimiter несовместим с --csv, --tsv и --whitespace")
+	case !slices.Contains([]string{"", formatText, formatCSV, formatJSON, formatNDJSON}, options.format):
+		return fmt.Errorf("неизвестный формат вывода %q: допустимы text, csv, json, ndjson", options.format)
+	case options.format != "" && options.format != formatText && !fieldMode:
+		return errors.New("флаг --format имеет смысл только с -f или -F")
+	case (options.keys != "" || options.inferTypes) && options.format != formatJSON && options.format != formatNDJSON:
+		return errors.New("флаги --names и --infer-types имеют смысл только с --format=json или ndjson")
+	case options.workers < 0:
+		return errors.New("число потоков -j не может быть отрицательным")
+	case options.workers > 1 && (options.csv || options.tsv || (options.format != "" && options.format != formatText)):
+		return errors.New("флаг -j поддерживается только для текстового ввода и вывода")
+	case options.zeroTerminated && (options.csv || options.tsv):
+		return errors.New("флаг -z несовместим с --csv и --tsv")
+	case options.lazyQuotes && !options.csv && !options.tsv:
+		return errors.New("флаг --lazy-quotes имеет смысл только с --csv или --tsv")
+	}
+	return nil
 }
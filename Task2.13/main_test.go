@@ -2,8 +2,16 @@ package main
 
 import (
 	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
 )
 
+// cutLine прогоняет одну строку через lineCutter; пропущенная (-s) строка даёт ""
+func cutLine(line string, options cutOptions, fields field.List, order field.Order) string {
+	out, _ := newLineCutter(options, fields, order).appendLine(nil, []byte(line))
+	return string(out)
+}
+
 func TestSelectFields(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -42,16 +50,70 @@ func TestSelectFields(t *testing.T) {
 			expected: "",
 		},
 		{
-			name:     "некорректные индексы в списке",
-			line:     "p q r",
-			options:  cutOptions{fields: "0,-1,abc,2", delimiter: " ", separated: false},
-			expected: "q",
+			name:     "диапазоны выводятся в порядке строки без повторов",
+			line:     "a,b,c,d,e,f,g",
+			options:  cutOptions{fields: "6-,2-3,-1,3", delimiter: ","},
+			expected: "a,b,c,f,g",
+		},
+		{
+			name:     "--complement выводит поля не из списка",
+			line:     "a,b,c,d,e",
+			options:  cutOptions{fields: "2-3", delimiter: ",", complement: true},
+			expected: "a,d,e",
+		},
+		{
+			name:     "--complement с многосимвольным разделителем",
+			line:     "a::b::c",
+			options:  cutOptions{fields: "-1", delimiter: "::", complement: true},
+			expected: "b::c",
 		},
 		{
-			name:     "разделитель таб, объединение через пробел",
+			name:     "разделитель таб, объединение через таб",
 			line:     "one\ttwo\tthree",
 			options:  cutOptions{fields: "1,3", delimiter: "\t", separated: false},
-			expected: "one three",
+			expected: "one\tthree",
+		},
+		{
+			name:     "таб сохраняет пустые поля",
+			line:     "a\t\tc\td",
+			options:  cutOptions{fields: "2-3", delimiter: "\t"},
+			expected: "\tc",
+		},
+		{
+			name:     "пробелы не разделяют поля в режиме таба",
+			line:     "first name\tlast name",
+			options:  cutOptions{fields: "2", delimiter: "\t"},
+			expected: "last name",
+		},
+		{
+			name:     "--output-delimiter",
+			line:     "a,b,c",
+			options:  cutOptions{fields: "1,3", delimiter: ",", outputDelimiter: " | "},
+			expected: "a | c",
+		},
+		{
+			name:     "--output-delimiter с многосимвольным разделителем",
+			line:     "a::b::c",
+			options:  cutOptions{fields: "2-", delimiter: "::", outputDelimiter: "\t"},
+			expected: "b\tc",
+		},
+		{
+			name:     "--whitespace схлопывает пробелы и табуляции",
+			line:     "  root   1\t 0.0  /sbin/init",
+			options:  cutOptions{fields: "1,4", delimiter: "\t", whitespace: true},
+			expected: "root /sbin/init",
+		},
+		{
+			name:     "--whitespace с --output-delimiter",
+			line:     "a  b\tc",
+			options:  cutOptions{fields: "1-", delimiter: "\t", whitespace: true, outputDelimiter: ","},
+			expected: "a,b,c",
+		},
+		{
+			name:     "--whitespace и -s отбрасывает строки без пробелов",
+			line:     "single",
+			options:  cutOptions{fields: "1", delimiter: "\t", whitespace: true, separated: true},
+			expected: "",
 		},
 		{
 			name:     "separated=true, строка без разделителя → отбрасывается",
@@ -69,8 +131,15 @@ func TestSelectFields(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fieldsIdx := parseFieldsList(tt.options.fields)
-			result := selectFields(tt.line, tt.options, fieldsIdx)
+			var fields field.List
+			if tt.options.fields != "" {
+				var err error
+				fields, err = field.ParseList(tt.options.fields)
+				if err != nil {
+					t.Fatalf("field.ParseList(%q): %v", tt.options.fields, err)
+				}
+			}
+			result := cutLine(tt.line, tt.options, fields, nil)
 			if result != tt.expected {
 				t.Errorf("ожидалось %q, получили %q", tt.expected, result)
 			}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+// writeBufferSize - размер буфера вывода
+const writeBufferSize = 64 << 10
+
+// Форматы вывода (--format)
+const (
+	formatText   = "text"
+	formatCSV    = "csv"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// recordWriter выводит выбранные поля записей в заданном формате
+type recordWriter interface {
+	// WriteHeader выводит запись-заголовок (с --header)
+	WriteHeader(record []string) error
+	// Write выводит очередную запись
+	Write(record []string) error
+	// Flush дописывает буферизованный вывод и завершает документ
+	Flush() error
+}
+
+// newRecordWriter создаёт writer для формата из options. Без --format
+// построчный вход выводится текстом, а вход --csv/--tsv - в том же формате.
+// Вывод буферизуется до Flush.
+func newRecordWriter(w io.Writer, options cutOptions) (recordWriter, error) {
+	switch options.format {
+	case formatJSON, formatNDJSON:
+		jw := &jsonRecordWriter{w: bufio.NewWriterSize(w, writeBufferSize), ndjson: options.format == formatNDJSON, inferTypes: options.inferTypes}
+		if options.keys != "" {
+			jw.names, jw.fixedNames = strings.Split(options.keys, ","), true
+		}
+		return jw, nil
+	case formatCSV:
+		return newCSVRecordWriter(w, ",", options.outputDelimiter)
+	}
+	if options.csv || options.tsv {
+		return newCSVRecordWriter(w, options.delimiter, options.outputDelimiter)
+	}
+	return &textRecordWriter{
+		w:    bufio.NewWriterSize(w, writeBufferSize),
+		sep:  options.outputSeparator(),
+		term: options.terminator(),
+	}, nil
+}
+
+// textRecordWriter соединяет поля разделителем вывода, одна запись на строку
+type textRecordWriter struct {
+	w    *bufio.Writer
+	sep  string
+	term byte
+}
+
+func (t *textRecordWriter) WriteHeader(record []string) error { return t.Write(record) }
+
+func (t *textRecordWriter) Write(record []string) error {
+	for i, field := range record {
+		if i > 0 {
+			t.w.WriteString(t.sep)
+		}
+		t.w.WriteString(field)
+	}
+	return t.w.WriteByte(t.term)
+}
+
+// writeCut выводит выбранную часть записи, собирая её прямо в буфере writer'а
+func (t *textRecordWriter) writeCut(c *lineCutter, line []byte) error {
+	buf, ok := c.appendLine(t.w.AvailableBuffer(), line)
+	if !ok {
+		return nil
+	}
+	_, err := t.w.Write(append(buf, t.term))
+	return err
+}
+
+func (t *textRecordWriter) Flush() error { return t.w.Flush() }
+
+// csvRecordWriter выводит записи по RFC 4180
+type csvRecordWriter struct {
+	*csv.Writer
+}
+
+// newCSVRecordWriter создаёт csv-writer; разделитель - outputDelimiter, если задан, иначе comma
+func newCSVRecordWriter(w io.Writer, comma, outputDelimiter string) (*csvRecordWriter, error) {
+	if outputDelimiter != "" {
+		comma = outputDelimiter
+	}
+	r, err := field.CSVComma(comma)
+	if err != nil {
+		return nil, err
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = r
+	return &csvRecordWriter{writer}, nil
+}
+
+func (c *csvRecordWriter) WriteHeader(record []string) error { return c.Write(record) }
+
+func (c *csvRecordWriter) Flush() error {
+	c.Writer.Flush()
+	return c.Error()
+}
+
+// jsonRecordWriter выводит записи JSON-массивом (json) или по одной
+// на строку (ndjson). Если известны имена колонок, запись становится
+// объектом, иначе массивом строк.
+type jsonRecordWriter struct {
+	w          *bufio.Writer
+	names      []string // ключи объектов; nil - записи выводятся массивами
+	fixedNames bool     // имена заданы --names, заголовок их не меняет
+	ndjson     bool
+	inferTypes bool // выводить числа и true/false как значения JSON
+	count      int
+	buf        []byte
+}
+
+func (j *jsonRecordWriter) WriteHeader(record []string) error {
+	if !j.fixedNames {
+		j.names = slices.Clone(record)
+	}
+	return nil
+}
+
+func (j *jsonRecordWriter) Write(record []string) error {
+	b := j.buf[:0]
+	switch {
+	case j.ndjson:
+	case j.count == 0:
+		b = append(b, "[\n"...)
+	default:
+		b = append(b, ",\n"...)
+	}
+
+	if j.names == nil {
+		b = append(b, '[')
+		for i, value := range record {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = j.appendValue(b, value)
+		}
+		b = append(b, ']')
+	} else {
+		b = append(b, '{')
+		for i, value := range record {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			// полям сверх списка имён ключом служит их номер в выводе
+			key := strconv.Itoa(i + 1)
+			if i < len(j.names) {
+				key = j.names[i]
+			}
+			b = appendJSONString(b, key)
+			b = append(b, ':')
+			b = j.appendValue(b, value)
+		}
+		b = append(b, '}')
+	}
+
+	if j.ndjson {
+		b = append(b, '\n')
+	}
+	j.buf = b
+	j.count++
+	_, err := j.w.Write(b)
+	return err
+}
+
+func (j *jsonRecordWriter) Flush() error {
+	if !j.ndjson {
+		end := "\n]\n"
+		if j.count == 0 {
+			end = "[]\n"
+		}
+		j.w.WriteString(end)
+	}
+	return j.w.Flush()
+}
+
+// appendValue добавляет значение поля; с inferTypes целые, дробные числа
+// и true/false выводятся без кавычек. Числом считается только значение в
+// канонической записи: "007" или "+5" остаются строками, иначе потерялись бы
+// ведущие нули в индексах, кодах и телефонах
+func (j *jsonRecordWriter) appendValue(b []byte, value string) []byte {
+	if j.inferTypes {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(n, 10) == value {
+			return append(b, value...)
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) &&
+			(strconv.FormatFloat(f, 'f', -1, 64) == value || strconv.FormatFloat(f, 'g', -1, 64) == value) {
+			return append(b, value...)
+		}
+		if value == "true" || value == "false" {
+			return append(b, value...)
+		}
+	}
+	return appendJSONString(b, value)
+}
+
+// appendJSONString добавляет строку в кавычках с экранированием JSON
+func appendJSONString(b []byte, s string) []byte {
+	// для строки json.Marshal не возвращает ошибок
+	quoted, _ := json.Marshal(s)
+	return append(b, quoted...)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+func TestOutputFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  cutOptions
+		expected string
+	}{
+		{
+			name:     "ndjson с заголовком",
+			input:    "id,name,city\n1,bob,Berlin\n2,\"Doe, J\",Paris\n",
+			options:  cutOptions{names: "name,id", delimiter: ",", header: true, csv: true, format: formatNDJSON},
+			expected: "{\"id\":\"1\",\"name\":\"bob\"}\n{\"id\":\"2\",\"name\":\"Doe, J\"}\n",
+		},
+		{
+			name:     "json с --reorder и --infer-types",
+			input:    "id\tname\tscore\tactive\n7\tbob\t9.5\ttrue\n",
+			options:  cutOptions{names: "name,score,active,id", delimiter: "\t", header: true, reorder: true, format: formatJSON, inferTypes: true},
+			expected: "[\n{\"name\":\"bob\",\"score\":9.5,\"active\":true,\"id\":7}\n]\n",
+		},
+		{
+			name:     "без заголовка - массивы",
+			input:    "a b c\nd e f\n",
+			options:  cutOptions{fields: "1,3", delimiter: " ", format: formatJSON},
+			expected: "[\n[\"a\",\"c\"],\n[\"d\",\"f\"]\n]\n",
+		},
+		{
+			name:     "--names задаёт ключи, лишние поля получают номер",
+			input:    "1:2:3\n",
+			options:  cutOptions{fields: "1-", delimiter: ":", format: formatNDJSON, keys: "x,y"},
+			expected: "{\"x\":\"1\",\"y\":\"2\",\"3\":\"3\"}\n",
+		},
+		{
+			name:     "--infer-types не трогает строки и спецзначения",
+			input:    "NaN,True,,Inf\n",
+			options:  cutOptions{fields: "1-", delimiter: ",", format: formatNDJSON, inferTypes: true},
+			expected: "[\"NaN\",\"True\",\"\",\"Inf\"]\n",
+		},
+		{
+			name:     "--infer-types сохраняет неканонические числа строками",
+			input:    "007,+5,1e3,1.50,.5,00\n",
+			options:  cutOptions{fields: "1-", delimiter: ",", format: formatNDJSON, inferTypes: true},
+			expected: "[\"007\",\"+5\",\"1e3\",\"1.50\",\".5\",\"00\"]\n",
+		},
+		{
+			name:     "--infer-types для канонических чисел",
+			input:    "zip,n,x,y\n007,-12,0.25,1e+21\n",
+			options:  cutOptions{fields: "1-", delimiter: ",", header: true, format: formatNDJSON, inferTypes: true},
+			expected: "{\"zip\":\"007\",\"n\":-12,\"x\":0.25,\"y\":1e+21}\n",
+		},
+		{
+			name:     "строка без разделителя - одно значение",
+			input:    "nodelim\na,b\n",
+			options:  cutOptions{fields: "2", delimiter: ",", format: formatNDJSON},
+			expected: "[\"nodelim\"]\n[\"b\"]\n",
+		},
+		{
+			name:     "CSV-запись из одного поля выводится целиком",
+			input:    "solo\na,b\n",
+			options:  cutOptions{fields: "2", delimiter: ",", csv: true, format: formatNDJSON},
+			expected: "[\"solo\"]\n[\"b\"]\n",
+		},
+		{
+			name:     "пустой вход в json",
+			input:    "",
+			options:  cutOptions{fields: "1", delimiter: ",", format: formatJSON},
+			expected: "[]\n",
+		},
+		{
+			name:     "csv из текстового входа",
+			input:    "a;b,c;d\n",
+			options:  cutOptions{fields: "2-", delimiter: ";", format: formatCSV},
+			expected: "\"b,c\",d\n",
+		},
+		{
+			name:     "csv с заголовком выводит его первой строкой",
+			input:    "id name\n1 bob\n",
+			options:  cutOptions{names: "name", delimiter: " ", header: true, format: formatCSV},
+			expected: "name\nbob\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields field.List
+			var order field.Order
+			if !tt.options.header {
+				var err error
+				if fields, order, err = resolveFields(tt.options, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			out, err := runCut(tt.input, tt.options, fields, order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.expected {
+				t.Errorf("получили %q, ожидалось %q", out, tt.expected)
+			}
+			if tt.options.format == formatJSON && !json.Valid([]byte(out)) {
+				t.Errorf("вывод не является корректным JSON: %q", out)
+			}
+		})
+	}
+}
+
+func TestValidateFormatOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options cutOptions
+		wantErr bool
+	}{
+		{name: "json с -f", options: cutOptions{fields: "1", format: formatJSON, keys: "a", inferTypes: true}},
+		{name: "text с -c", options: cutOptions{chars: "1", format: formatText}},
+		{name: "неизвестный формат", options: cutOptions{fields: "1", format: "xml"}, wantErr: true},
+		{name: "json с -b", options: cutOptions{bytes: "1", format: formatJSON}, wantErr: true},
+		{name: "--names без json", options: cutOptions{fields: "1", format: formatCSV, keys: "a"}, wantErr: true},
+		{name: "--infer-types без json", options: cutOptions{fields: "1", inferTypes: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOptions(tt.options, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ошибка %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+// batchSize - объём пачки записей, которую обрабатывает один поток при -j
+const batchSize = 256 << 10
+
+// cutBatch - пачка целых записей и результат её обработки; сигнал о
+// готовности приходит в done
+type cutBatch struct {
+	data []byte
+	out  []byte
+	done chan struct{}
+}
+
+// cutParallel обрабатывает оставшиеся записи пачками в options.workers потоков.
+// Пачки выводятся строго в порядке чтения, поэтому вывод совпадает
+// с последовательным. Ошибка записи прекращает чтение.
+func cutParallel(records *recordReader, tw *textRecordWriter, options cutOptions, fields field.List, order field.Order) error {
+	pool := sync.Pool{New: func() any {
+		return &cutBatch{done: make(chan struct{}, 1)}
+	}}
+	queue := make(chan *cutBatch)
+	// pending хранит пачки в порядке вывода и ограничивает число пачек в работе
+	pending := make(chan *cutBatch, options.workers*2)
+
+	var wg sync.WaitGroup
+	for range options.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// у каждого потока свои буферы разбиения
+			cutter := newLineCutter(options, fields, order)
+			for b := range queue {
+				b.out = cutter.appendBatch(b.out[:0], b.data, tw.term)
+				b.done <- struct{}{}
+			}
+		}()
+	}
+
+	var failed atomic.Bool
+	writeErr := make(chan error, 1)
+	go func() {
+		var err error
+		for b := range pending {
+			<-b.done
+			if err == nil {
+				if _, err = tw.w.Write(b.out); err != nil {
+					failed.Store(true)
+				}
+			}
+			pool.Put(b)
+		}
+		writeErr <- err
+	}()
+
+	var readErr error
+	for readErr == nil && !failed.Load() {
+		b := pool.Get().(*cutBatch)
+		b.data, readErr = records.readBatch(b.data[:0], batchSize)
+		if len(b.data) == 0 {
+			pool.Put(b)
+			continue
+		}
+		pending <- b
+		queue <- b
+	}
+	close(queue)
+	close(pending)
+	wg.Wait()
+
+	if err := <-writeErr; err != nil {
+		return err
+	}
+	if readErr == io.EOF {
+		return nil
+	}
+	return readErr
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+// readBufferSize - размер буфера чтения; записи длиннее собираются в recordReader.buf
+const readBufferSize = 64 << 10
+
+// recordReader читает записи до байта-терминатора без ограничения длины.
+// Возвращаемый срез действителен до следующего чтения.
+type recordReader struct {
+	r    *bufio.Reader
+	term byte
+	buf  []byte // запись, не поместившаяся в буфер bufio.Reader
+}
+
+func newRecordReader(r io.Reader, term byte) *recordReader {
+	return &recordReader{r: bufio.NewReaderSize(r, readBufferSize), term: term}
+}
+
+// next возвращает очередную запись без терминатора; в конце входа - io.EOF
+func (rr *recordReader) next() ([]byte, error) {
+	rr.buf = rr.buf[:0]
+	for {
+		chunk, err := rr.r.ReadSlice(rr.term)
+		if err == bufio.ErrBufferFull {
+			rr.buf = append(rr.buf, chunk...)
+			continue
+		}
+		if len(rr.buf) > 0 {
+			rr.buf = append(rr.buf, chunk...)
+			chunk = rr.buf
+		}
+		switch {
+		case err == nil:
+			return chunk[:len(chunk)-1], nil
+		case err == io.EOF && len(chunk) > 0:
+			// последняя запись без терминатора
+			return chunk, nil
+		}
+		return nil, err
+	}
+}
+
+// readBatch дописывает в dst целые записи вместе с терминаторами, пока dst
+// не достигнет size байт. В конце входа возвращает io.EOF вместе с остатком.
+func (rr *recordReader) readBatch(dst []byte, size int) ([]byte, error) {
+	// partial - последний кусок записи прочитан без терминатора: запись,
+	// длиннее буфера bufio.Reader, нельзя разрывать между пачками
+	for partial := false; len(dst) < size || partial; {
+		chunk, err := rr.r.ReadSlice(rr.term)
+		dst = append(dst, chunk...)
+		partial = err == bufio.ErrBufferFull
+		if err != nil && !partial {
+			return dst, err
+		}
+	}
+	return dst, nil
+}
+
+// lineCutter выбирает части записей для текстового вывода. Список разобран
+// заранее, а буферы переиспользуются между записями, поэтому на запись память
+// не выделяется (кроме -E: regexp возвращает позиции совпадений новым срезом).
+type lineCutter struct {
+	options  cutOptions
+	splitter field.Splitter
+	fields   field.List
+	order    field.Order
+	sep      []byte
+	limit    int      // сколько полей нужно выделить из записи
+	parts    [][]byte // поля текущей записи
+	indexes  []int    // номера полей текущей записи для --reorder
+}
+
+func newLineCutter(options cutOptions, fields field.List, order field.Order) *lineCutter {
+	c := &lineCutter{
+		options:  options,
+		splitter: options.splitter(),
+		fields:   fields,
+		order:    order,
+		sep:      []byte(options.outputSeparator()),
+		limit:    field.OpenEnd,
+	}
+	// поля правее последнего выбранного не нужны, их можно не искать
+	switch {
+	case options.reorder:
+		c.limit = order.Last()
+	case !options.complement && len(fields) > 0:
+		c.limit = fields[len(fields)-1].End
+	}
+	return c
+}
+
+// appendLine добавляет к dst выбранную часть записи line. false означает,
+// что запись без разделителя пропускается (-s).
+func (c *lineCutter) appendLine(dst, line []byte) ([]byte, bool) {
+	o := &c.options
+	switch {
+	case o.bytes != "":
+		return appendBytes(dst, line, c.fields, o.complement, o.noSplit), true
+	case o.chars != "":
+		return appendChars(dst, line, c.fields, o.complement), true
+	case !c.splitter.HasDelimiter(line):
+		// как в GNU cut: запись без разделителя выводится целиком, если нет -s
+		if o.separated {
+			return dst, false
+		}
+		return append(dst, line...), true
+	case len(c.fields) == 0 && len(c.order) == 0:
+		// без списка полей (только -s) запись выводится целиком
+		return append(dst, line...), true
+	}
+
+	first := true
+	for _, part := range c.selected(line) {
+		if !first {
+			dst = append(dst, c.sep...)
+		}
+		dst = append(dst, part...)
+		first = false
+	}
+	return dst, true
+}
+
+// appendParts добавляет к dst выбранные поля записи отдельными строками
+// (для --format=csv, json и ndjson)
+func (c *lineCutter) appendParts(dst []string, line []byte) []string {
+	if !c.splitter.HasDelimiter(line) {
+		// запись без разделителя - одно значение, как и в текстовом выводе
+		return append(dst, string(line))
+	}
+	for _, part := range c.selected(line) {
+		dst = append(dst, string(part))
+	}
+	return dst
+}
+
+// selected разбивает запись и возвращает выбранные поля: в порядке строки
+// или, с --reorder, в порядке запроса. Результат действителен до следующего вызова.
+func (c *lineCutter) selected(line []byte) [][]byte {
+	// текстовый ввод разбирается без CSV, поэтому ошибки быть не может
+	parts, _ := c.splitter.Split(c.parts[:0], line, c.limit)
+	c.parts = parts
+	n := len(parts)
+
+	if c.options.reorder {
+		c.indexes = c.order.Indexes(c.indexes[:0], n)
+		for _, i := range c.indexes {
+			parts = append(parts, parts[i])
+		}
+	} else {
+		for i := range n {
+			if c.fields.Includes(i+1) != c.options.complement {
+				parts = append(parts, parts[i])
+			}
+		}
+	}
+	// выбранные поля дописаны после всех полей записи в тот же буфер
+	c.parts = parts
+	return parts[n:]
+}
+
+// appendBatch обрабатывает пачку записей, разделённых term, и дописывает
+// результат в dst с тем же терминатором
+func (c *lineCutter) appendBatch(dst, data []byte, term byte) []byte {
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, term); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+		var ok bool
+		if dst, ok = c.appendLine(dst, line); ok {
+			dst = append(dst, term)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+// makeReport генерирует строки отчёта с разделителем ";" общим объёмом около size байт
+func makeReport(size int) []byte {
+	var b bytes.Buffer
+	for i := 0; b.Len() < size; i++ {
+		fmt.Fprintf(&b, "%d;2024-01-%02d;Иванов И.И.;%d.%02d;RUB;;оплата по счёту №%d\n", i, i%28+1, i*37%100000, i%100, i)
+	}
+	return b.Bytes()
+}
+
+func TestLineCutterAllocs(t *testing.T) {
+	line := []byte("12;2024-01-13;Иванов И.И.;444.12;RUB;;оплата  по\tсчёту")
+	tests := []struct {
+		name    string
+		options cutOptions
+	}{
+		{name: "односимвольный разделитель", options: cutOptions{fields: "1,4-", delimiter: ";"}},
+		{name: "многосимвольный разделитель", options: cutOptions{fields: "2", delimiter: ";2"}},
+		{name: "--complement", options: cutOptions{fields: "3", delimiter: ";", complement: true}},
+		{name: "--reorder", options: cutOptions{fields: "4,1", delimiter: ";", reorder: true}},
+		{name: "--whitespace", options: cutOptions{fields: "2-", whitespace: true, outputDelimiter: ","}},
+		{name: "-s", options: cutOptions{fields: "1", delimiter: "|", separated: true}},
+		{name: "-b -n", options: cutOptions{bytes: "1-20", noSplit: true}},
+		{name: "-c", options: cutOptions{chars: "15-25"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, order, err := resolveFields(tt.options, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cutter := newLineCutter(tt.options, fields, order)
+			dst := make([]byte, 0, 256)
+			cutter.appendLine(dst, line) // прогрев буфера полей
+
+			allocs := testing.AllocsPerRun(100, func() {
+				dst, _ = cutter.appendLine(dst[:0], line)
+			})
+			if allocs != 0 {
+				t.Errorf("выделений памяти на строку: %v, ожидалось 0", allocs)
+			}
+		})
+	}
+}
+
+func TestCutTextParallelMatchesSequential(t *testing.T) {
+	// несколько пачек, запись длиннее буфера чтения на границе пачки
+	// и последняя строка без перевода строки
+	report := string(makeReport(3 * batchSize))
+	long := "long;" + strings.Repeat("x;", readBufferSize) + "\n"
+	boundary := strings.LastIndexByte(report[:batchSize-readBufferSize/2], '\n') + 1
+	input := report[:boundary] + long + report[boundary:] + "last;x;y"
+	tests := []struct {
+		name    string
+		options cutOptions
+	}{
+		{name: "поля", options: cutOptions{fields: "1,4,7", delimiter: ";"}},
+		{name: "-s и --output-delimiter", options: cutOptions{fields: "2-3", delimiter: "№", separated: true, outputDelimiter: "|"}},
+		{name: "--header и --reorder", options: cutOptions{fields: "5,1", delimiter: ";", header: true, reorder: true}},
+		{name: "-E", options: cutOptions{fields: "2", delimiterRe: regexp.MustCompile(`;+`)}},
+		{name: "-z", options: cutOptions{fields: "1", delimiter: "\n", zeroTerminated: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields field.List
+			var order field.Order
+			if !tt.options.header {
+				var err error
+				if fields, order, err = resolveFields(tt.options, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			sequential, err := runCut(input, tt.options, fields, order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.options.workers = 4
+			parallel, err := runCut(input, tt.options, fields, order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sequential != parallel {
+				t.Errorf("вывод -j отличается от последовательного: %d и %d байт", len(parallel), len(sequential))
+			}
+		})
+	}
+}
+
+func TestRecordReader(t *testing.T) {
+	long := strings.Repeat("x", readBufferSize*2+10)
+	records := newRecordReader(strings.NewReader("a\n"+long+"\n\nb"), '\n')
+
+	var got []string
+	for {
+		line, err := records.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(line))
+	}
+
+	expected := []string{"a", long, "", "b"}
+	if len(got) != len(expected) {
+		t.Fatalf("получили %d записей, ожидалось %d", len(got), len(expected))
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("запись %d: длина %d, ожидалась %d", i, len(got[i]), len(expected[i]))
+		}
+	}
+}
+
+func BenchmarkCutText(b *testing.B) {
+	input := makeReport(16 << 20)
+	options := cutOptions{fields: "1,4-5", delimiter: ";"}
+	fields, order, _ := resolveFields(options, nil)
+
+	run := func(b *testing.B, workers int) {
+		options.workers = workers
+		b.SetBytes(int64(len(input)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			out, _ := newRecordWriter(io.Discard, options)
+			if err := cutText(bytes.NewReader(input), out, options, fields, order); err != nil {
+				b.Fatal(err)
+			}
+			out.Flush()
+		}
+	}
+
+	b.Run("sequential", func(b *testing.B) { run(b, 1) })
+	b.Run(fmt.Sprintf("parallel-%d", runtime.GOMAXPROCS(0)), func(b *testing.B) { run(b, runtime.GOMAXPROCS(0)) })
+}
+
+func BenchmarkLineCutter(b *testing.B) {
+	line := []byte("12;2024-01-13;Иванов И.И.;444.12;RUB;;оплата по счёту №12")
+	benchmarks := []struct {
+		name    string
+		options cutOptions
+	}{
+		{name: "fields", options: cutOptions{fields: "1,4-5", delimiter: ";"}},
+		{name: "complement", options: cutOptions{fields: "3", delimiter: ";", complement: true}},
+		{name: "whitespace", options: cutOptions{fields: "2", whitespace: true}},
+		{name: "chars", options: cutOptions{chars: "4-13"}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			fields, order, _ := resolveFields(bm.options, nil)
+			cutter := newLineCutter(bm.options, fields, order)
+			dst, _ := cutter.appendLine(make([]byte, 0, 256), line)
+			b.SetBytes(int64(len(line)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				dst, _ = cutter.appendLine(dst[:0], line)
+			}
+		})
+	}
+}
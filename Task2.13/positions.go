@@ -0,0 +1,48 @@
+package main
+
+import (
+	"unicode/utf8"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+// appendBytes добавляет к dst байты строки, выбранные списком позиций (-b).
+// С noSplit (-n) многобайтовый символ не разрезается: он выводится,
+// только если выбраны все его байты.
+func appendBytes(dst, line []byte, positions field.List, complement, noSplit bool) []byte {
+	for i := 0; i < len(line); {
+		size := 1
+		if noSplit {
+			_, size = utf8.DecodeRune(line[i:])
+		}
+		if selectsAll(positions, i+1, i+size, complement) {
+			dst = append(dst, line[i:i+size]...)
+		}
+		i += size
+	}
+	return dst
+}
+
+// appendChars добавляет к dst символы строки, выбранные списком позиций (-c).
+// Позиции считаются в рунах UTF-8; некорректный байт считается одним символом.
+func appendChars(dst, line []byte, positions field.List, complement bool) []byte {
+	n := 1
+	for i := 0; i < len(line); n++ {
+		_, size := utf8.DecodeRune(line[i:])
+		if positions.Includes(n) != complement {
+			dst = append(dst, line[i:i+size]...)
+		}
+		i += size
+	}
+	return dst
+}
+
+// selectsAll сообщает, выбраны ли все позиции от from до to включительно
+func selectsAll(l field.List, from, to int, complement bool) bool {
+	for n := from; n <= to; n++ {
+		if l.Includes(n) == complement {
+			return false
+		}
+	}
+	return true
+}
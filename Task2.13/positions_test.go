@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+func TestSelectBytes(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		list       string
+		complement bool
+		noSplit    bool
+		expected   string
+	}{
+		{name: "фиксированная ширина", line: "20240101ACME  0001500", list: "1-8,15-", expected: "202401010001500"},
+		{name: "за пределами строки", line: "abc", list: "5-", expected: ""},
+		{name: "разрезает кириллицу без -n", line: "жук", list: "1", expected: "\xd0"},
+		{name: "-n не разрезает символ", line: "жук", list: "1-3", noSplit: true, expected: "ж"},
+		{name: "-n с целыми символами", line: "жук", list: "3-4", noSplit: true, expected: "у"},
+		{name: "-n и ASCII", line: "ab-жу", list: "2-4", noSplit: true, expected: "b-"},
+		{name: "--complement", line: "abcdef", list: "2-3", complement: true, expected: "adef"},
+		{name: "--complement с -n", line: "aжb", list: "2", complement: true, noSplit: true, expected: "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positions, err := field.ParseList(tt.list)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := string(appendBytes(nil, []byte(tt.line), positions, tt.complement, tt.noSplit))
+			if result != tt.expected {
+				t.Errorf("получили %q, ожидалось %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSelectChars(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		list       string
+		complement bool
+		expected   string
+	}{
+		{name: "кириллица", line: "Иванов   1500", list: "1-6", expected: "Иванов"},
+		{name: "открытый диапазон", line: "счёт№42", list: "5-", expected: "№42"},
+		{name: "--complement", line: "ёжик", list: "1", complement: true, expected: "жик"},
+		{name: "некорректный UTF-8 считается одним символом", line: "a\xffб", list: "2-", expected: "\xffб"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positions, err := field.ParseList(tt.list)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := string(appendChars(nil, []byte(tt.line), positions, tt.complement))
+			if result != tt.expected {
+				t.Errorf("получили %q, ожидалось %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      cutOptions
+		delimiterSet bool
+		wantErr      bool
+	}{
+		{name: "только -f", options: cutOptions{fields: "1"}},
+		{name: "только -s", options: cutOptions{separated: true}},
+		{name: "-b и -n", options: cutOptions{bytes: "1-4", noSplit: true}},
+		{name: "ни одного режима", options: cutOptions{}, wantErr: true},
+		{name: "-b и -c вместе", options: cutOptions{bytes: "1", chars: "2"}, wantErr: true},
+		{name: "-f и -c вместе", options: cutOptions{fields: "1", chars: "2"}, wantErr: true},
+		{name: "-s с -c", options: cutOptions{chars: "1", separated: true}, wantErr: true},
+		{name: "-d с -b", options: cutOptions{bytes: "1"}, delimiterSet: true, wantErr: true},
+		{name: "--output-delimiter с -c", options: cutOptions{chars: "1", outputDelimiter: ","}, wantErr: true},
+		{name: "--whitespace с -d", options: cutOptions{fields: "1", whitespace: true}, delimiterSet: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOptions(tt.options, tt.delimiterSet)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ошибка %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Package fieldtest содержит общий набор случаев разбиения строк на поля.
+// Тесты пакета field, cut и sort прогоняют его через свои реализации, чтобы
+// утилиты одинаково понимали, что такое «колонка N».
+package fieldtest
+
+import (
+	"regexp"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+)
+
+// Case - строка, правила разбиения и ожидаемые поля
+type Case struct {
+	Name     string
+	Splitter field.Splitter
+	Line     string
+	// Fields - все поля строки по порядку
+	Fields []string
+}
+
+// Column возвращает ожидаемое значение колонки n (с 1) или "", если её нет
+func (c Case) Column(n int) string {
+	if n < 1 || n > len(c.Fields) {
+		return ""
+	}
+	return c.Fields[n-1]
+}
+
+// Delimited сообщает, что случай использует простой разделитель
+// (режим по умолчанию, без Whitespace, Regexp и CSV)
+func (c Case) Delimited() bool {
+	return !c.Splitter.Whitespace && c.Splitter.Regexp == nil && !c.Splitter.CSV
+}
+
+// Cases - общий набор случаев
+var Cases = []Case{
+	{
+		Name:     "табуляция сохраняет пустые поля",
+		Splitter: field.Splitter{Delimiter: "\t"},
+		Line:     "a\t\tc\t",
+		Fields:   []string{"a", "", "c", ""},
+	},
+	{
+		Name:     "пробелы не разделяют поля в режиме табуляции",
+		Splitter: field.Splitter{Delimiter: "\t"},
+		Line:     "first name\tlast name",
+		Fields:   []string{"first name", "last name"},
+	},
+	{
+		Name:     "строка без разделителя - одно поле",
+		Splitter: field.Splitter{Delimiter: ","},
+		Line:     "abc",
+		Fields:   []string{"abc"},
+	},
+	{
+		Name:     "пустая строка - одно пустое поле",
+		Splitter: field.Splitter{Delimiter: ","},
+		Line:     "",
+		Fields:   []string{""},
+	},
+	{
+		Name:     "разделители по краям",
+		Splitter: field.Splitter{Delimiter: ","},
+		Line:     ",a,",
+		Fields:   []string{"", "a", ""},
+	},
+	{
+		Name:     "кириллица и точка с запятой",
+		Splitter: field.Splitter{Delimiter: ";"},
+		Line:     "Иванов;Пётр;;1500",
+		Fields:   []string{"Иванов", "Пётр", "", "1500"},
+	},
+	{
+		Name:     "многосимвольный разделитель",
+		Splitter: field.Splitter{Delimiter: "::"},
+		Line:     "a::b:::c",
+		Fields:   []string{"a", "b", ":c"},
+	},
+	{
+		Name:     "вхождения разделителя не перекрываются",
+		Splitter: field.Splitter{Delimiter: "aa"},
+		Line:     "xaaay",
+		Fields:   []string{"x", "ay"},
+	},
+	{
+		Name:     "многобайтовый разделитель",
+		Splitter: field.Splitter{Delimiter: "│"},
+		Line:     "id│имя│",
+		Fields:   []string{"id", "имя", ""},
+	},
+	{
+		Name:     "группы пробелов и табуляций",
+		Splitter: field.Splitter{Whitespace: true},
+		Line:     "  root   1\t 0.0  /sbin/init ",
+		Fields:   []string{"root", "1", "0.0", "/sbin/init"},
+	},
+	{
+		Name:     "пустая строка в пробельном режиме не содержит полей",
+		Splitter: field.Splitter{Whitespace: true},
+		Line:     "",
+		Fields:   nil,
+	},
+	{
+		Name:     "регулярное выражение с пробелами вокруг",
+		Splitter: field.Splitter{Regexp: regexp.MustCompile(`\s*[;|]\s*`)},
+		Line:     "alpha ; beta|gamma  ;delta",
+		Fields:   []string{"alpha", "beta", "gamma", "delta"},
+	},
+	{
+		Name:     "регулярное выражение с ведущим разделителем",
+		Splitter: field.Splitter{Regexp: regexp.MustCompile(` +`)},
+		Line:     "  1234 pts/0",
+		Fields:   []string{"", "1234", "pts/0"},
+	},
+	{
+		Name:     "CSV с кавычками",
+		Splitter: field.Splitter{Delimiter: ",", CSV: true},
+		Line:     `1,"Smith, John","say ""hi"""`,
+		Fields:   []string{"1", "Smith, John", `say "hi"`},
+	},
+	{
+		Name:     "CSV с пустыми полями",
+		Splitter: field.Splitter{Delimiter: ",", CSV: true},
+		Line:     "a,,",
+		Fields:   []string{"a", "", ""},
+	},
+	{
+		Name:     "TSV с табуляцией в кавычках",
+		Splitter: field.Splitter{Delimiter: "\t", CSV: true},
+		Line:     "a\t\"b\tc\"\td",
+		Fields:   []string{"a", "b\tc", "d"},
+	},
+}
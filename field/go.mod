@@ -0,0 +1,3 @@
+module github.com/PavelBradnitski/WbTechL2/field
+
+go 1.24.1
@@ -0,0 +1,167 @@
+// Package field разбирает строки на поля и списки полей так, как это делают
+// утилиты cut и sort из этого репозитория: одни и те же правила для табуляции,
+// пустых полей, многосимвольных разделителей, пробельного, CSV и regexp режимов,
+// а также общий синтаксис списков LIST (N, N-, N-M, -M).
+package field
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenEnd - конец диапазона "N-": до последнего поля строки
+const OpenEnd = math.MaxInt
+
+// Range - диапазон номеров [Start, End], нумерация с 1
+type Range struct {
+	Start int
+	End   int
+}
+
+// List - отсортированные непересекающиеся диапазоны из списка LIST
+type List []Range
+
+// Order - диапазоны в порядке запроса, без объединения (для вывода в заданном порядке)
+type Order []Range
+
+// ParseList разбирает LIST: номера и диапазоны через запятую (N, N-, N-M, -M).
+// Пересекающиеся и соседние диапазоны объединяются, поэтому поля выводятся
+// в порядке строки, каждое один раз.
+func ParseList(list string) (List, error) {
+	items, err := ParseItems(list, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	return Merge(items), nil
+}
+
+// ParseItems разбирает элементы списка в порядке запроса. Элемент,
+// совпадающий с именем колонки из header, заменяется её номером;
+// с namesOnly допускаются только имена.
+func ParseItems(list string, header []string, namesOnly bool) (Order, error) {
+	if list == "" {
+		return nil, errors.New("пустой список полей")
+	}
+
+	var items Order
+	for _, item := range strings.Split(list, ",") {
+		if i := slices.Index(header, item); i >= 0 {
+			items = append(items, Range{Start: i + 1, End: i + 1})
+			continue
+		}
+		if namesOnly {
+			return nil, fmt.Errorf("нет колонки %q, доступны: %s", item, strings.Join(header, ", "))
+		}
+		r, err := parseRange(item)
+		if err != nil {
+			if header != nil {
+				return nil, fmt.Errorf("%w; доступны колонки: %s", err, strings.Join(header, ", "))
+			}
+			return nil, err
+		}
+		items = append(items, r)
+	}
+	return items, nil
+}
+
+// Merge сортирует диапазоны и объединяет пересекающиеся и соседние.
+// Для пустого списка возвращает nil.
+func Merge(items Order) List {
+	if len(items) == 0 {
+		return nil
+	}
+	ranges := List(slices.Clone(items))
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Start < ranges[j].Start
+	})
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if last.End == OpenEnd || r.Start <= last.End+1 {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// parseRange разбирает один элемент списка
+func parseRange(item string) (Range, error) {
+	startText, endText, isRange := strings.Cut(item, "-")
+	if !isRange {
+		n, err := parseNumber(item)
+		return Range{Start: n, End: n}, err
+	}
+	if startText == "" && endText == "" {
+		return Range{}, errors.New("диапазон без границ: -")
+	}
+
+	r := Range{Start: 1, End: OpenEnd}
+	var err error
+	if startText != "" {
+		if r.Start, err = parseNumber(startText); err != nil {
+			return r, err
+		}
+	}
+	if endText != "" {
+		if r.End, err = parseNumber(endText); err != nil {
+			return r, err
+		}
+	}
+	if r.End < r.Start {
+		return r, fmt.Errorf("убывающий диапазон: %s", item)
+	}
+	return r, nil
+}
+
+// parseNumber разбирает номер поля; поля нумеруются с 1
+func parseNumber(text string) (int, error) {
+	n, err := strconv.Atoi(text)
+	if err != nil || strings.HasPrefix(text, "+") {
+		return 0, fmt.Errorf("некорректный номер: %q", text)
+	}
+	if n < 1 {
+		return 0, errors.New("поля нумеруются с 1")
+	}
+	return n, nil
+}
+
+// Includes сообщает, входит ли номер n в список
+func (l List) Includes(n int) bool {
+	for _, r := range l {
+		if n < r.Start {
+			return false
+		}
+		if n <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Last возвращает номер последнего поля, которое может понадобиться
+// (OpenEnd для открытого диапазона, 0 для пустого порядка)
+func (o Order) Last() int {
+	last := 0
+	for _, r := range o {
+		last = max(last, r.End)
+	}
+	return last
+}
+
+// Indexes добавляет в dst индексы (с 0) полей записи из n полей в порядке
+// запроса; несуществующие поля пропускаются
+func (o Order) Indexes(dst []int, n int) []int {
+	for _, r := range o {
+		for i := r.Start; i <= min(r.End, n); i++ {
+			dst = append(dst, i-1)
+		}
+	}
+	return dst
+}
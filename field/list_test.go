@@ -0,0 +1,65 @@
+package field
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     string
+		expected List
+	}{
+		{name: "одно поле", list: "3", expected: List{{Start: 3, End: 3}}},
+		{name: "все формы диапазонов", list: "-2,4-5,7-", expected: List{{Start: 1, End: 2}, {Start: 4, End: 5}, {Start: 7, End: OpenEnd}}},
+		{name: "пересечения и соседние объединяются", list: "5-7,2-3,4,6-9", expected: List{{Start: 2, End: 9}}},
+		{name: "открытый диапазон поглощает следующие", list: "3-,5,10-12", expected: List{{Start: 3, End: OpenEnd}}},
+		{name: "повторы", list: "2,2,1", expected: List{{Start: 1, End: 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseList(tt.list)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("получили %v, ожидалось %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseListErrors(t *testing.T) {
+	for _, list := range []string{"", "0", "0-3", "abc", "1,,2", "-", "5-2", "+1", "1-x", "0,-1,abc,2"} {
+		if _, err := ParseList(list); err == nil {
+			t.Errorf("для списка %q ожидалась ошибка", list)
+		}
+	}
+}
+
+func TestOrderIndexes(t *testing.T) {
+	order, err := ParseItems("3,1,5-", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order.Indexes(nil, 6); !reflect.DeepEqual(got, []int{2, 0, 4, 5}) {
+		t.Errorf("для 6 полей получили %v", got)
+	}
+	if got := order.Indexes(nil, 2); !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("для 2 полей получили %v", got)
+	}
+	if order.Last() != OpenEnd {
+		t.Errorf("Last() = %d, ожидался OpenEnd", order.Last())
+	}
+}
+
+func TestMergeEmpty(t *testing.T) {
+	if got := Merge(nil); got != nil {
+		t.Errorf("Merge(nil) = %v, ожидался nil", got)
+	}
+	if got := Merge(Order{}); got != nil {
+		t.Errorf("Merge(Order{}) = %v, ожидался nil", got)
+	}
+}
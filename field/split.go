@@ -0,0 +1,196 @@
+package field
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Splitter разбивает строку на поля. Режим выбирается полями структуры:
+// Regexp, Whitespace, CSV или, по умолчанию, разделитель Delimiter.
+// Нулевой Delimiter в режиме по умолчанию не совпадает ни с чем: вся строка -
+// одно поле.
+type Splitter struct {
+	// Delimiter - разделитель полей: каждое вхождение отделяет поле, пустые
+	// поля сохраняются, пробелы не считаются разделителем. В режиме CSV -
+	// символ-разделитель записи.
+	Delimiter string
+	// Whitespace - поля разделяются группами пробелов и табуляций,
+	// пробелы по краям строки отбрасываются
+	Whitespace bool
+	// Regexp - поля разделяются совпадениями выражения (см. CompileDelimiter)
+	Regexp *regexp.Regexp
+	// CSV - строка разбирается как запись RFC 4180 с разделителем Delimiter
+	CSV bool
+	// LazyQuotes - в режиме CSV допускать кавычки внутри неэкранированных полей
+	LazyQuotes bool
+}
+
+// Split добавляет в dst поля строки line, но не больше limit (limit < 1 -
+// все поля). Поля ссылаются на line, кроме режима CSV, где значения
+// раскавычиваются в новую память; ошибку возвращает только режим CSV.
+// В режиме по умолчанию и Whitespace память не выделяется, если в dst
+// хватает места.
+func (s Splitter) Split(dst [][]byte, line []byte, limit int) ([][]byte, error) {
+	if limit < 1 {
+		limit = OpenEnd
+	}
+	start := len(dst)
+
+	switch {
+	case s.CSV:
+		return s.splitCSV(dst, line, limit)
+	case s.Whitespace:
+		for i := 0; i < len(line) && len(dst)-start < limit; {
+			for i < len(line) && isBlank(line[i]) {
+				i++
+			}
+			from := i
+			for i < len(line) && !isBlank(line[i]) {
+				i++
+			}
+			if i > from {
+				dst = append(dst, line[from:i])
+			}
+		}
+	case s.Regexp != nil:
+		from := 0
+		for _, loc := range s.Regexp.FindAllIndex(line, limit) {
+			dst = append(dst, line[from:loc[0]])
+			from = loc[1]
+			if len(dst)-start == limit {
+				return dst, nil
+			}
+		}
+		dst = append(dst, line[from:])
+	default:
+		delim := s.Delimiter
+		for len(dst)-start < limit {
+			i := -1
+			if delim != "" {
+				i = bytes.Index(line, []byte(delim))
+			}
+			if i < 0 {
+				dst = append(dst, line)
+				break
+			}
+			dst = append(dst, line[:i])
+			line = line[i+len(delim):]
+		}
+	}
+	return dst, nil
+}
+
+// splitCSV разбирает строку как одну запись CSV; пустая строка не содержит полей
+func (s Splitter) splitCSV(dst [][]byte, line []byte, limit int) ([][]byte, error) {
+	reader, err := s.NewCSVReader(bytes.NewReader(line))
+	if err != nil {
+		return dst, err
+	}
+	record, err := reader.Read()
+	if err == io.EOF {
+		return dst, nil
+	}
+	if err != nil {
+		return dst, err
+	}
+	for _, value := range record[:min(len(record), limit)] {
+		dst = append(dst, []byte(value))
+	}
+	return dst, nil
+}
+
+// Field возвращает поле номер n (с 1) или "", если такого поля нет
+// (в том числе при ошибке разбора CSV). В режиме по умолчанию память
+// не выделяется.
+func (s Splitter) Field(line string, n int) string {
+	if n < 1 {
+		return ""
+	}
+	if s.CSV || s.Whitespace || s.Regexp != nil {
+		parts, _ := s.Split(nil, []byte(line), n)
+		if n > len(parts) {
+			return ""
+		}
+		return string(parts[n-1])
+	}
+
+	for ; n > 1; n-- {
+		i := -1
+		if s.Delimiter != "" {
+			i = strings.Index(line, s.Delimiter)
+		}
+		if i < 0 {
+			return ""
+		}
+		line = line[i+len(s.Delimiter):]
+	}
+	if s.Delimiter != "" {
+		if i := strings.Index(line, s.Delimiter); i >= 0 {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// HasDelimiter сообщает, есть ли в строке хотя бы один разделитель полей
+func (s Splitter) HasDelimiter(line []byte) bool {
+	switch {
+	case s.Whitespace:
+		return bytes.ContainsAny(line, " \t")
+	case s.Regexp != nil:
+		return s.Regexp.Match(line)
+	case s.Delimiter == "":
+		return false
+	}
+	return bytes.Contains(line, []byte(s.Delimiter))
+}
+
+// NewCSVReader создаёт csv.Reader с разделителем Delimiter и LazyQuotes.
+// Записи могут содержать разное число полей и переводы строк в кавычках.
+func (s Splitter) NewCSVReader(r io.Reader) (*csv.Reader, error) {
+	comma, err := CSVComma(s.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.LazyQuotes = s.LazyQuotes
+	reader.FieldsPerRecord = -1
+	return reader, nil
+}
+
+// CSVComma проверяет, что разделитель CSV - один символ, допустимый для encoding/csv
+func CSVComma(delimiter string) (rune, error) {
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) || comma == utf8.RuneError {
+		return 0, errors.New("разделитель CSV должен быть одним символом")
+	}
+	if comma == '"' || comma == '\r' || comma == '\n' {
+		return 0, fmt.Errorf("недопустимый разделитель CSV: %q", comma)
+	}
+	return comma, nil
+}
+
+// CompileDelimiter компилирует регулярное выражение разделителя. Выражение,
+// совпадающее с пустой строкой, разбило бы строку на отдельные символы.
+func CompileDelimiter(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if re.MatchString("") {
+		return nil, fmt.Errorf("выражение %q совпадает с пустой строкой", pattern)
+	}
+	return re, nil
+}
+
+// isBlank сообщает, разделяет ли байт поля в режиме Whitespace
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
@@ -0,0 +1,121 @@
+package field_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/PavelBradnitski/WbTechL2/field"
+	"github.com/PavelBradnitski/WbTechL2/field/fieldtest"
+)
+
+func TestSplitConformance(t *testing.T) {
+	for _, c := range fieldtest.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			parts, err := c.Splitter.Split(nil, []byte(c.Line), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(parts) != len(c.Fields) {
+				t.Fatalf("получили %d полей %q, ожидалось %q", len(parts), parts, c.Fields)
+			}
+			for i, part := range parts {
+				if string(part) != c.Fields[i] {
+					t.Errorf("поле %d: получили %q, ожидалось %q", i+1, part, c.Fields[i])
+				}
+			}
+
+			for n := 1; n <= len(c.Fields)+1; n++ {
+				if got := c.Splitter.Field(c.Line, n); got != c.Column(n) {
+					t.Errorf("Field(%d) = %q, ожидалось %q", n, got, c.Column(n))
+				}
+			}
+		})
+	}
+}
+
+func TestSplitLimit(t *testing.T) {
+	splitters := []field.Splitter{
+		{Delimiter: ","},
+		{Whitespace: true},
+		{Regexp: regexp.MustCompile(`,`)},
+		{Delimiter: ",", CSV: true},
+	}
+	for _, s := range splitters {
+		line := []byte("a,b,c,d")
+		if s.Whitespace {
+			line = []byte("a b c d")
+		}
+		parts, err := s.Split(nil, line, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) != 2 || string(parts[0]) != "a" || string(parts[1]) != "b" {
+			t.Errorf("%+v: получили %q, ожидалось [a b]", s, parts)
+		}
+	}
+}
+
+func TestHasDelimiter(t *testing.T) {
+	tests := []struct {
+		splitter field.Splitter
+		line     string
+		expected bool
+	}{
+		{splitter: field.Splitter{Delimiter: "\t"}, line: "a b", expected: false},
+		{splitter: field.Splitter{Delimiter: "::"}, line: "a::b", expected: true},
+		{splitter: field.Splitter{Whitespace: true}, line: "a\tb", expected: true},
+		{splitter: field.Splitter{Regexp: regexp.MustCompile(`[;|]`)}, line: "a-b", expected: false},
+		{splitter: field.Splitter{}, line: "a b", expected: false},
+	}
+	for _, tt := range tests {
+		if got := tt.splitter.HasDelimiter([]byte(tt.line)); got != tt.expected {
+			t.Errorf("%+v для %q: получили %v", tt.splitter, tt.line, got)
+		}
+	}
+}
+
+func TestSplitCSVError(t *testing.T) {
+	s := field.Splitter{Delimiter: ",", CSV: true}
+	if _, err := s.Split(nil, []byte(`a,b "c" d`), 0); err == nil {
+		t.Error("ожидалась ошибка разбора CSV")
+	}
+	s.LazyQuotes = true
+	if _, err := s.Split(nil, []byte(`a,b "c" d`), 0); err != nil {
+		t.Errorf("с LazyQuotes ошибки быть не должно: %v", err)
+	}
+	for _, delimiter := range []string{"::", "\"", "\n", ""} {
+		if _, err := field.CSVComma(delimiter); err == nil {
+			t.Errorf("для разделителя %q ожидалась ошибка", delimiter)
+		}
+	}
+}
+
+func TestCompileDelimiter(t *testing.T) {
+	if _, err := field.CompileDelimiter(`\s*[;|]\s*`); err != nil {
+		t.Errorf("неожиданная ошибка: %v", err)
+	}
+	for _, pattern := range []string{`\s*`, `(`, `x?`} {
+		if _, err := field.CompileDelimiter(pattern); err == nil {
+			t.Errorf("для %q ожидалась ошибка", pattern)
+		}
+	}
+}
+
+func TestSplitAllocs(t *testing.T) {
+	line := "12;2024-01-13;Иванов И.И.;444.12;RUB;;оплата"
+	for _, s := range []field.Splitter{{Delimiter: ";"}, {Delimiter: ";2"}, {Whitespace: true}} {
+		b := []byte(line)
+		dst := make([][]byte, 0, 16)
+		allocs := testing.AllocsPerRun(100, func() {
+			dst, _ = s.Split(dst[:0], b, 0)
+		})
+		if allocs != 0 {
+			t.Errorf("%+v: Split выделяет память: %v", s, allocs)
+		}
+	}
+
+	s := field.Splitter{Delimiter: ";"}
+	if allocs := testing.AllocsPerRun(100, func() { s.Field(line, 4) }); allocs != 0 {
+		t.Errorf("Field выделяет память: %v", allocs)
+	}
+}